@@ -0,0 +1,70 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Error is an error reported to the client. It is sent as
+//
+//	{"error": {"code": "POST_NOT_FOUND", "message": "Post not found"}}
+//
+// with the HTTP status of its code, and for VALIDATION_FAILED a "details"
+// object holding a message for each invalid field
+type Error struct {
+	Status  int               `json:"-"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// New returns an error with the given code, sent with the status that code always has
+func New(code, message string) *Error {
+	status, ok := statuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Internal reports a failure on the server's side, such as a database error
+func Internal(message string) *Error {
+	return New(InternalError, message)
+}
+
+// Validation reports the fields of a request that are invalid, with a message
+// for each keyed by its JSON path, e.g. "recipe.ingredients[0].name"
+func Validation(details map[string]string) *Error {
+	return New(ValidationFailed, "Some fields are invalid").WithDetails(details)
+}
+
+// WithDetails returns a copy of the error carrying a message for each of the given fields
+func (e *Error) WithDetails(details map[string]string) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Write sends the error as the JSON error envelope. Errors that are not an
+// *Error are logged and sent as INTERNAL_ERROR, without their text, which is
+// not meant for clients
+func Write(w http.ResponseWriter, err error) {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		log.Printf("Internal error: %v", err)
+		appErr = Internal("Something went wrong")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.Status)
+	json.NewEncoder(w).Encode(struct {
+		Error *Error `json:"error"`
+	}{appErr})
+}
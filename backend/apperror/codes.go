@@ -0,0 +1,106 @@
+package apperror
+
+import "net/http"
+
+// Error codes. Messages are written for people and may be reworded, but codes
+// are stable: clients can rely on them, so they are never renamed or reused,
+// and each is always sent with the same HTTP status
+const (
+	// 400: the request body or a form could not be read
+	InvalidInput = "INVALID_INPUT"
+	// 400: some fields are invalid; details holds a message for each
+	ValidationFailed = "VALIDATION_FAILED"
+	// 400: an ID in the path is malformed
+	InvalidID = "INVALID_ID"
+	// 400: a query parameter, such as a sort, limit or search, is invalid
+	InvalidQuery = "INVALID_QUERY"
+	// 400: the user has as many API keys or collections, or a collection as many posts, as allowed
+	LimitReached = "LIMIT_REACHED"
+	// 400: users can't follow themselves
+	CannotFollowSelf = "CANNOT_FOLLOW_SELF"
+	// 400: admins can't change their own role
+	CannotChangeOwnRole = "CANNOT_CHANGE_OWN_ROLE"
+	// 400: replies can't be added to deleted comments
+	CommentDeleted = "COMMENT_DELETED"
+	// 400: two-factor authentication must be set up before it can be enabled
+	TwoFactorSetupRequired = "TWO_FACTOR_SETUP_REQUIRED"
+	// 400: two-factor authentication is already on
+	TwoFactorAlreadyEnabled = "TWO_FACTOR_ALREADY_ENABLED"
+	// 400: two-factor authentication is off
+	TwoFactorNotEnabled = "TWO_FACTOR_NOT_ENABLED"
+
+	// 401: the request has no valid access token or API key
+	Unauthenticated = "UNAUTHENTICATED"
+	// 401: the session or refresh token is no longer valid; sign in again
+	SessionExpired = "SESSION_EXPIRED"
+	// 401: the email, password or code is wrong; details may name the field
+	InvalidCredentials = "INVALID_CREDENTIALS"
+
+	// 403: the user may not do this
+	Forbidden = "FORBIDDEN"
+	// 403: the API key lacks the scope the route needs
+	MissingScope = "MISSING_SCOPE"
+	// 403: the route needs a signed-in session; API keys can't use it
+	APIKeyNotAllowed = "API_KEY_NOT_ALLOWED"
+
+	// 404: no route matches the path
+	NotFound               = "NOT_FOUND"
+	PostNotFound           = "POST_NOT_FOUND"
+	UserNotFound           = "USER_NOT_FOUND"
+	CommentNotFound        = "COMMENT_NOT_FOUND"
+	CollectionNotFound     = "COLLECTION_NOT_FOUND"
+	CollectionItemNotFound = "COLLECTION_ITEM_NOT_FOUND"
+	SessionNotFound        = "SESSION_NOT_FOUND"
+	APIKeyNotFound         = "API_KEY_NOT_FOUND"
+
+	// 405: the route doesn't support the method
+	MethodNotAllowed = "METHOD_NOT_ALLOWED"
+	// 409: the post is already in the collection
+	AlreadyInCollection = "ALREADY_IN_COLLECTION"
+	// 413: the upload is larger than allowed for its type
+	FileTooLarge = "FILE_TOO_LARGE"
+	// 415: the upload is not a supported image or video
+	UnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	// 422: the recipe can't be scaled as it doesn't say how many servings it makes
+	RecipeNotScalable = "RECIPE_NOT_SCALABLE"
+	// 429: too many failed sign-ins; the Retry-After header says when to try again
+	TooManyAttempts = "TOO_MANY_ATTEMPTS"
+
+	// 500: something failed on the server
+	InternalError = "INTERNAL_ERROR"
+)
+
+var statuses = map[string]int{
+	InvalidInput:            http.StatusBadRequest,
+	ValidationFailed:        http.StatusBadRequest,
+	InvalidID:               http.StatusBadRequest,
+	InvalidQuery:            http.StatusBadRequest,
+	LimitReached:            http.StatusBadRequest,
+	CannotFollowSelf:        http.StatusBadRequest,
+	CannotChangeOwnRole:     http.StatusBadRequest,
+	CommentDeleted:          http.StatusBadRequest,
+	TwoFactorSetupRequired:  http.StatusBadRequest,
+	TwoFactorAlreadyEnabled: http.StatusBadRequest,
+	TwoFactorNotEnabled:     http.StatusBadRequest,
+	Unauthenticated:         http.StatusUnauthorized,
+	SessionExpired:          http.StatusUnauthorized,
+	InvalidCredentials:      http.StatusUnauthorized,
+	Forbidden:               http.StatusForbidden,
+	MissingScope:            http.StatusForbidden,
+	APIKeyNotAllowed:        http.StatusForbidden,
+	NotFound:                http.StatusNotFound,
+	PostNotFound:            http.StatusNotFound,
+	UserNotFound:            http.StatusNotFound,
+	CommentNotFound:         http.StatusNotFound,
+	CollectionNotFound:      http.StatusNotFound,
+	CollectionItemNotFound:  http.StatusNotFound,
+	SessionNotFound:         http.StatusNotFound,
+	APIKeyNotFound:          http.StatusNotFound,
+	MethodNotAllowed:        http.StatusMethodNotAllowed,
+	AlreadyInCollection:     http.StatusConflict,
+	FileTooLarge:            http.StatusRequestEntityTooLarge,
+	UnsupportedMediaType:    http.StatusUnsupportedMediaType,
+	RecipeNotScalable:       http.StatusUnprocessableEntity,
+	TooManyAttempts:         http.StatusTooManyRequests,
+	InternalError:           http.StatusInternalServerError,
+}
@@ -0,0 +1,28 @@
+// Command reconcile recomputes the reaction counters stored on posts from the
+// reactions collection, repairing any drift.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	err := godotenv.Load()
+
+	if err != nil {
+		log.Fatal("Couldn't load the env file")
+	}
+
+	db := config.ConnectDB()
+
+	if err := repositories.ReconcileReactionCounts(context.Background(), db); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Reaction counters reconciled")
+}
@@ -0,0 +1,50 @@
+// Command setrole changes the role of the user with the given email, which is
+// how the first admin is made:
+//
+//	go run ./cmd/setrole someone@example.com admin
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) != 3 || !models.IsRole(os.Args[2]) {
+		log.Fatal("usage: setrole <email> <user|moderator|admin>")
+	}
+	email, role := os.Args[1], os.Args[2]
+
+	err := godotenv.Load()
+
+	if err != nil {
+		log.Fatal("Couldn't load the env file")
+	}
+
+	db := config.ConnectDB()
+	users := repositories.NewMongoUserRepository(db)
+	sessions := repositories.NewMongoSessionRepository(db)
+
+	ctx := context.Background()
+	user, err := users.FindByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("Could not find user %s: %v", email, err)
+	}
+
+	if err := users.SetRole(ctx, user.ID, role); err != nil {
+		log.Fatal(err)
+	}
+
+	// access tokens carry the role, so the user signs in again to pick up the new one
+	if err := sessions.RevokeAllByUser(ctx, user.ID); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("%s is now %s", email, role)
+}
@@ -5,13 +5,14 @@ import (
 	"log"
 	"os"
 
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var DB *mongo.Database
-
-func ConnectDB() {
+// connects to MongoDB and prepares the indexes the repositories rely on
+func ConnectDB() *mongo.Database {
 	mongoURI := os.Getenv("MONGODB_URI")
 	if mongoURI == "" {
 		log.Fatal("MongoDB URI is not set properly")
@@ -28,6 +29,241 @@ func ConnectDB() {
 		log.Fatal(err)
 	}
 
-	DB = client.Database("urcuisine")
+	db := client.Database("urcuisine")
 	log.Println("Connected to MongoDB database")
+
+	if err := createSearchIndex(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createReactionIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createCommentIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createSessionIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createTokenIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createAPIKeyIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createLoginEventIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createUserIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createFollowIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createCollectionIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := createMediaIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := backfillAuthorNames(db); err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// creates the weighted text index used by post search
+func createSearchIndex(db *mongo.Database) error {
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "recipe.ingredients.name", Value: "text"},
+			{Key: "recipe.steps.text", Value: "text"},
+			{Key: "country", Value: "text"},
+			{Key: "authorName", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("posts_text_search").
+			SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "country", Value: 6},
+				{Key: "recipe.ingredients.name", Value: 4},
+				{Key: "recipe.steps.text", Value: 1},
+				{Key: "authorName", Value: 3},
+				{Key: "description", Value: 2},
+			}),
+	}
+
+	indexes := db.Collection("posts").Indexes()
+	_, err := indexes.CreateOne(context.Background(), index)
+
+	// an older definition of the index is replaced, since MongoDB allows only
+	// one text index per collection
+	if commandErr, ok := err.(mongo.CommandError); ok && (commandErr.Code == 85 || commandErr.Code == 86) {
+		if _, err := indexes.DropOne(context.Background(), "posts_text_search"); err != nil {
+			return err
+		}
+		_, err = indexes.CreateOne(context.Background(), index)
+		return err
+	}
+
+	return err
+}
+
+// a user can have each kind of reaction on a post at most once
+func createReactionIndexes(db *mongo.Database) error {
+	_, err := db.Collection("reactions").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userID", Value: 1}, {Key: "postID", Value: 1}, {Key: "kind", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "postID", Value: 1}, {Key: "kind", Value: 1}},
+		},
+	})
+	return err
+}
+
+// threads are listed per post in creation order, and their replies are loaded by root
+func createCommentIndexes(db *mongo.Database) error {
+	_, err := db.Collection("comments").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "postID", Value: 1}, {Key: "parentID", Value: 1}, {Key: "created", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "rootID", Value: 1}},
+		},
+	})
+	return err
+}
+
+// sessions are listed per user
+func createSessionIndexes(db *mongo.Database) error {
+	_, err := db.Collection("sessions").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "userID", Value: 1}, {Key: "lastUsedAt", Value: -1}},
+	})
+	return err
+}
+
+// tokens are looked up by their hash when used, and replaced per user and purpose
+func createTokenIndexes(db *mongo.Database) error {
+	_, err := db.Collection("userTokens").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "purpose", Value: 1}},
+		},
+	})
+	return err
+}
+
+// API keys are listed per user
+func createAPIKeyIndexes(db *mongo.Database) error {
+	_, err := db.Collection("apiKeys").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+	})
+	return err
+}
+
+// login history is listed per user, newest first
+func createLoginEventIndexes(db *mongo.Database) error {
+	_, err := db.Collection("loginEvents").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "userID", Value: 1}, {Key: "createdAt", Value: -1}},
+	})
+	return err
+}
+
+// handles are unique, but accounts created before handles existed may lack one
+// until the user profiles migration gives them one
+func createUserIndexes(db *mongo.Database) error {
+	_, err := db.Collection("users").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "handle", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+	return err
+}
+
+// a user follows each cook or country at most once, and followers are counted per target
+func createFollowIndexes(db *mongo.Database) error {
+	_, err := db.Collection("follows").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "followerID", Value: 1}, {Key: "kind", Value: 1}, {Key: "target", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "target", Value: 1}},
+		},
+	})
+	return err
+}
+
+// collections are listed per user, and a deleted post is looked up in all of them
+func createCollectionIndexes(db *mongo.Database) error {
+	_, err := db.Collection("collections").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userID", Value: 1}, {Key: "updatedAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "items.postID", Value: 1}},
+		},
+	})
+	return err
+}
+
+// images still waiting for their variants are looked up on startup, and posts
+// are found by the media attached to them once the variants are made
+func createMediaIndexes(db *mongo.Database) error {
+	if _, err := db.Collection("media").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"status": models.MediaPending}),
+	}); err != nil {
+		return err
+	}
+
+	_, err := db.Collection("posts").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "media.id", Value: 1}},
+	})
+	return err
+}
+
+// copies the author's name onto posts created before it was stored on the post,
+// so that searching by author name works for older posts as well
+func backfillAuthorNames(db *mongo.Database) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"authorName": bson.M{"$exists": false}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "userID",
+			"foreignField": "_id",
+			"as":           "author",
+		}}},
+		{{Key: "$unwind", Value: "$author"}},
+		{{Key: "$project", Value: bson.M{"authorName": "$author.name"}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           "posts",
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+
+	cursor, err := db.Collection("posts").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(context.Background())
 }
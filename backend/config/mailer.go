@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/mailer"
+)
+
+// returns the SMTP mailer when SMTP_HOST is set, and otherwise one that writes
+// emails to MAIL_DIR (default "mail") for local development
+func NewMailer() mailer.Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "urcuisine <no-reply@urcuisine.local>"
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return mailer.NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+	}
+
+	dir := os.Getenv("MAIL_DIR")
+	if dir == "" {
+		dir = "mail"
+	}
+	return mailer.NewFileMailer(dir, from)
+}
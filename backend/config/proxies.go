@@ -0,0 +1,31 @@
+package config
+
+import (
+	"net"
+	"os"
+	"strings"
+)
+
+// reports whether ip belongs to one of the reverse proxies listed in the
+// comma-separated TRUSTED_PROXIES variable, as addresses or CIDR ranges. Only
+// these are believed about the client address in X-Forwarded-For
+func IsTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if proxy := net.ParseIP(entry); proxy != nil && proxy.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
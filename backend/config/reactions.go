@@ -0,0 +1,25 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+var defaultReactionKinds = []string{"like", "dislike", "made_it", "want_to_try", "delicious", "too_spicy"}
+
+// returns the reaction kinds users may leave on posts, taken from the
+// comma-separated REACTION_KINDS variable when it is set
+func ReactionKinds() []string {
+	value := os.Getenv("REACTION_KINDS")
+	if value == "" {
+		return defaultReactionKinds
+	}
+
+	var kinds []string
+	for _, kind := range strings.Split(value, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
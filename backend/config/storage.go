@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/storage"
+)
+
+// returns the S3 store when STORAGE_DRIVER is "s3", and otherwise one that keeps
+// uploads in MEDIA_DIR (default "uploads") and serves them from MEDIA_URL
+func NewBlobStore() storage.BlobStore {
+	if os.Getenv("STORAGE_DRIVER") == "s3" {
+		store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return store
+	}
+
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("MEDIA_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/media"
+	}
+	return storage.NewLocalStore(dir, baseURL)
+}
@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/mailer"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// emails a new single-use token to the user, replacing any sent earlier for the same purpose
+func (app *App) sendAccountToken(ctx context.Context, user models.User, purpose string) error {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	if err := app.Tokens.DeleteByUser(ctx, user.ID, purpose); err != nil {
+		return err
+	}
+
+	ttl, path, subject, text := verifyEmailTTL, "/verify-email", "Verify your email", "Welcome to urcuisine! Confirm your email address by opening the link below."
+	if purpose == models.TokenResetPassword {
+		ttl, path, subject, text = resetPasswordTTL, "/reset-password", "Reset your password", "Someone asked to reset your urcuisine password. If it was you, open the link below to choose a new one. Otherwise you can ignore this email."
+	}
+
+	now := time.Now()
+	err = app.Tokens.Create(ctx, models.UserToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Hash:      utils.HashToken(token),
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := os.Getenv("FRONTEND_URL") + path + "?token=" + url.QueryEscape(token)
+	return app.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n\n%s\n\nThe link expires in %s.\n", user.Name, text, link, humanDuration(ttl)),
+	})
+}
+
+func humanDuration(d time.Duration) string {
+	if d >= 2*time.Hour {
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return "1 hour"
+}
+
+// ForgotPassword emails a password reset link; the response is the same whether
+// or not the email belongs to an account
+func (app *App) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	user, err := app.Users.FindByEmail(r.Context(), body.Email)
+	if err == nil {
+		if err := app.sendAccountToken(r.Context(), user, models.TokenResetPassword); err != nil {
+			log.Printf("Could not send password reset email: %v", err)
+		}
+	} else if err != repositories.ErrNotFound {
+		apperror.Write(w, apperror.Internal("Error finding user"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "If that email has an account, a reset link is on its way"})
+}
+
+// ResetPassword sets a new password using the token from a reset email and signs out every session
+func (app *App) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	if err := validate.StructPartial(models.User{Password: body.Password}, "Password"); err != nil {
+		apperror.Write(w, apperror.Validation(map[string]string{"password": "Password must be at least 8 characters long, contain one number and one special character."}))
+		return
+	}
+
+	token, ok := app.consumeAccountToken(w, r, models.TokenResetPassword, body.Token)
+	if !ok {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error hashing password"))
+		return
+	}
+
+	if err := app.Users.SetPassword(r.Context(), token.UserID, string(hashedPassword)); err != nil {
+		apperror.Write(w, apperror.Internal("Could not reset password"))
+		return
+	}
+
+	// the lockout was there to protect the old password
+	if err := app.Users.ResetFailedLogins(r.Context(), token.UserID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not reset password"))
+		return
+	}
+
+	// whoever had the old password may still be signed in
+	if err := app.Sessions.RevokeAllByUser(r.Context(), token.UserID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not end sessions"))
+		return
+	}
+
+	// following the link proves the user owns the address
+	if err := app.Users.SetEmailVerified(r.Context(), token.UserID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not verify email"))
+		return
+	}
+
+	clearAuthCookies(w)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password reset successfully"})
+}
+
+// VerifyEmail marks the user's email as verified using the token from a verification email
+func (app *App) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	token, ok := app.consumeAccountToken(w, r, models.TokenVerifyEmail, body.Token)
+	if !ok {
+		return
+	}
+
+	if err := app.Users.SetEmailVerified(r.Context(), token.UserID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not verify email"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified successfully"})
+}
+
+// ResendVerification sends the signed-in user a new verification email
+func (app *App) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := primitive.ObjectIDFromHex(r.Context().Value("userID").(string))
+
+	user, err := app.Users.FindByID(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("User not found"))
+		return
+	}
+
+	if user.EmailVerified {
+		apperror.Write(w, apperror.Validation(map[string]string{"email": "Email is already verified"}))
+		return
+	}
+
+	if err := app.sendAccountToken(r.Context(), user, models.TokenVerifyEmail); err != nil {
+		apperror.Write(w, apperror.Internal("Could not send verification email"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Verification email sent"})
+}
+
+// uses up the token, writing the error response when it is unknown, used or expired
+func (app *App) consumeAccountToken(w http.ResponseWriter, r *http.Request, purpose, token string) (models.UserToken, bool) {
+	if token == "" {
+		apperror.Write(w, apperror.Validation(map[string]string{"token": "This field is required."}))
+		return models.UserToken{}, false
+	}
+
+	userToken, err := app.Tokens.Consume(r.Context(), purpose, utils.HashToken(token))
+	if err == repositories.ErrNotFound {
+		apperror.Write(w, apperror.Validation(map[string]string{"token": "This link is invalid or has expired."}))
+		return userToken, false
+	}
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not check token"))
+		return userToken, false
+	}
+
+	return userToken, true
+}
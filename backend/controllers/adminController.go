@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// users are listed a page at a time, unlike posts
+const (
+	defaultUserPageLimit = 10
+	maxUserPageLimit     = 50
+)
+
+// the fields of a user shown to admins, leaving out the password hash
+type adminUser struct {
+	ID            primitive.ObjectID `json:"id"`
+	Name          string             `json:"name"`
+	Email         string             `json:"email"`
+	Role          string             `json:"role"`
+	EmailVerified bool               `json:"emailVerified"`
+}
+
+// handles listing every user for admins
+func (app *App) ListUsers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	users, total, err := app.Users.List(r.Context(), (page-1)*limit, limit)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch users"))
+		return
+	}
+
+	response := make([]adminUser, 0, len(users))
+	for _, user := range users {
+		response = append(response, adminUser{
+			ID:            user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			Role:          user.Role,
+			EmailVerified: user.EmailVerified,
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users": response,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// handles changing a user's role
+func (app *App) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid user ID"))
+		return
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+	if !models.IsRole(body.Role) {
+		apperror.Write(w, apperror.Validation(map[string]string{"role": "Role must be user, moderator or admin."}))
+		return
+	}
+
+	// an admin demoting themselves could leave nobody able to manage roles
+	if userID.Hex() == r.Context().Value("userID").(string) && body.Role != models.RoleAdmin {
+		apperror.Write(w, apperror.New(apperror.CannotChangeOwnRole, "You cannot change your own role"))
+		return
+	}
+
+	err = app.Users.SetRole(r.Context(), userID, body.Role)
+	if err == repositories.ErrNotFound {
+		apperror.Write(w, apperror.New(apperror.UserNotFound, "User not found"))
+		return
+	}
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not change role"))
+		return
+	}
+
+	// access tokens carry the role, so the user's sessions are ended to make the change take effect
+	if err := app.Sessions.RevokeAllByUser(r.Context(), userID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not end sessions"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Role changed successfully"})
+}
+
+// handles deleting any post, along with its comments, reactions and place in collections
+func (app *App) AdminDeletePost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	if _, err := app.Posts.FindByID(r.Context(), postID); err != nil {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	if err := app.Posts.DeleteAny(r.Context(), postID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete post"))
+		return
+	}
+
+	if err := app.Comments.DeleteByPost(r.Context(), postID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete comments"))
+		return
+	}
+	if err := app.Reactions.DeleteByPost(r.Context(), postID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete reactions"))
+		return
+	}
+
+	if err := app.Collections.RemovePost(r.Context(), postID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not remove post from collections"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Post deleted successfully"})
+}
+
+// handles hiding a post from everyone, or showing it again
+func (app *App) SetPostHidden(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	hidden, ok := decodeHidden(w, r)
+	if !ok {
+		return
+	}
+
+	err = app.Posts.SetHidden(r.Context(), postID, hidden)
+	if err == repositories.ErrNotFound {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not update post"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]bool{"hidden": hidden})
+}
+
+// handles deleting any comment, which leaves the same placeholder as when its author deletes it
+func (app *App) AdminDeleteComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	comment, ok := app.findComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Comments.SoftDelete(r.Context(), comment.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete comment"))
+		return
+	}
+
+	if err := app.refreshCommentCount(r, comment.PostID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment count"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Comment deleted successfully"})
+}
+
+// handles hiding a comment, or showing it again
+func (app *App) SetCommentHidden(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	comment, ok := app.findComment(w, r)
+	if !ok {
+		return
+	}
+
+	hidden, ok := decodeHidden(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Comments.SetHidden(r.Context(), comment.ID, hidden); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment"))
+		return
+	}
+
+	if err := app.refreshCommentCount(r, comment.PostID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment count"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]bool{"hidden": hidden})
+}
+
+func (app *App) findComment(w http.ResponseWriter, r *http.Request) (models.Comment, bool) {
+	commentID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid comment ID"))
+		return models.Comment{}, false
+	}
+
+	comment, err := app.Comments.FindByID(r.Context(), commentID)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.CommentNotFound, "Comment not found"))
+		return models.Comment{}, false
+	}
+	return comment, true
+}
+
+func decodeHidden(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	var body struct {
+		Hidden *bool `json:"hidden"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Hidden == nil {
+		apperror.Write(w, apperror.Validation(map[string]string{"hidden": "This field is required."}))
+		return false, false
+	}
+	return *body.Hidden, true
+}
+
+// reads the page and limit query parameters, falling back to sensible defaults
+func parsePageParams(r *http.Request) (int, int, error) {
+	page, limit := 1, defaultUserPageLimit
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("Invalid page parameter")
+		}
+		page = parsed
+	}
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("Invalid limit parameter")
+		}
+		limit = parsed
+	}
+
+	if limit > maxUserPageLimit {
+		limit = maxUserPageLimit
+	}
+
+	return page, limit, nil
+}
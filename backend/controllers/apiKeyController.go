@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// keeps a leaked account from being used to mint keys without end
+const maxAPIKeysPerUser = 20
+
+// handles creating an API key; the key itself is only ever shown in this response
+func (app *App) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	var body struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	errorMessages := make(map[string]string)
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" || len(body.Name) > 100 {
+		errorMessages["name"] = "Name is required and must be at most 100 characters long."
+	}
+	if len(body.Scopes) == 0 {
+		errorMessages["scopes"] = "At least one scope is required."
+	}
+	for _, scope := range body.Scopes {
+		if !models.IsScope(scope) {
+			errorMessages["scopes"] = "Scopes must be posts:read, posts:write or comments:write."
+		}
+	}
+	if len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
+		return
+	}
+
+	existing, err := app.APIKeys.ListActiveByUser(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch API keys"))
+		return
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		apperror.Write(w, apperror.New(apperror.LimitReached, "You have too many API keys; revoke one first"))
+		return
+	}
+
+	key := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      body.Name,
+		Scopes:    uniqueScopes(body.Scopes),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	secret, err := utils.GenerateAPIKey(key.ID.Hex())
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error generating API key"))
+		return
+	}
+	key.Hash = utils.HashToken(secret)
+
+	if err := app.APIKeys.Create(r.Context(), key); err != nil {
+		apperror.Write(w, apperror.Internal("Could not create API key"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		models.APIKey
+		Key string `json:"key"`
+	}{key, secret})
+}
+
+// handles listing the user's API keys, without the keys themselves
+func (app *App) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	keys, err := app.APIKeys.ListActiveByUser(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch API keys"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"apiKeys": keys})
+}
+
+// handles revoking one of the user's API keys
+func (app *App) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	keyID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid API key ID"))
+		return
+	}
+
+	key, err := app.APIKeys.FindByID(r.Context(), keyID)
+	if err != nil || key.UserID != userID || key.RevokedAt != 0 {
+		apperror.Write(w, apperror.New(apperror.APIKeyNotFound, "API key not found"))
+		return
+	}
+
+	if err := app.APIKeys.Revoke(r.Context(), keyID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not revoke API key"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "API key revoked successfully"})
+}
+
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
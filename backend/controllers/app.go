@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/images"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/mailer"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// App holds the dependencies shared by the HTTP handlers
+type App struct {
+	Posts       repositories.PostRepository
+	Users       repositories.UserRepository
+	Sessions    repositories.SessionRepository
+	APIKeys     repositories.APIKeyRepository
+	Reactions   repositories.ReactionRepository
+	Comments    repositories.CommentRepository
+	Follows     repositories.FollowRepository
+	Collections repositories.CollectionRepository
+	Media       repositories.MediaRepository
+	Tokens      repositories.TokenRepository
+	LoginEvents repositories.LoginEventRepository
+	Mailer      mailer.Mailer
+	Blobs       storage.BlobStore
+	Images      *images.Pipeline
+}
+
+// returns the ID of the user the verified access token belongs to, if any
+func currentUserID(r *http.Request) (primitive.ObjectID, bool) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		return primitive.NilObjectID, false
+	}
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	return objectID, err == nil
+}
@@ -1,106 +1,137 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 
-	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
 	"github.com/go-playground/validator/v10"
 )
 
 var validate = validator.New()
 
+var signupMessages = map[string]string{
+	"email":    "Invalid email format. Please enter a valid email.",
+	"password": "Password must be at least 8 characters long, contain one number and one special character.",
+	"name":     "Name is required.",
+}
+
 // Signup function to register a new user
-func Signup(w http.ResponseWriter, r *http.Request) {
+func (app *App) Signup(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
+	// only the profile fields are taken from the body; the rest of the user is set here
+	var body struct {
+		profileFields
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
 		return
 	}
 
-	err = validate.Struct(user)
-
-	if err != nil {
-		errorMessages := make(map[string]string)
-
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Field() {
-			case "Email":
-				errorMessages["email"] = "Invalid email format. Please enter a valid email."
-			case "Password":
-				errorMessages["password"] = "Password must be at least 8 characters long, contain one number and one special character."
-			case "Name":
-				errorMessages["name"] = "Name is required."
-			default:
-				errorMessages[err.Field()] = "Invalid input."
+	// a blank handle means one is picked from the name
+	if body.Handle != nil && strings.TrimSpace(*body.Handle) == "" {
+		body.Handle = nil
+	}
+	var profile models.ProfileUpdate
+	errorMessages := body.apply(&profile)
+
+	user := models.User{
+		Name:     profile.Name,
+		Email:    body.Email,
+		Password: body.Password,
+		Handle:   profile.Handle,
+		Bio:      profile.Bio,
+		Avatar:   profile.Avatar,
+		Country:  profile.Country,
+	}
+	if err := validate.Struct(user); err != nil {
+		// the form explains the rules rather than the rule that was broken
+		for field, message := range fieldErrors(err) {
+			if override, ok := signupMessages[field]; ok {
+				message = override
+			}
+			if _, ok := errorMessages[field]; !ok {
+				errorMessages[field] = message
 			}
 		}
+	}
 
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(errorMessages)
+	if len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
 		return
 	}
 
-	collection := config.DB.Collection("users")
-
-	var existingUser models.User
-	err = collection.FindOne(context.Background(), bson.M{"email": user.Email}).Decode(&existingUser)
+	_, err = app.Users.FindByEmail(r.Context(), user.Email)
 
 	if err == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"email": "Email already exists"})
+		apperror.Write(w, apperror.Validation(map[string]string{"email": "Email already exists"}))
 		return
-	} else if err != mongo.ErrNoDocuments {
-		http.Error(w, "Error checking existing user", http.StatusInternalServerError)
+	} else if err != repositories.ErrNotFound {
+		apperror.Write(w, apperror.Internal("Error checking existing user"))
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, "Error hashing password", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Error hashing password"))
 		return
 	}
 	user.Password = string(hashedPassword)
+	user.EmailVerified = false
+	user.Role = models.RoleUser
+	user.CreatedAt = time.Now().Unix()
+
+	// a handle that was asked for is checked when the user is created
+	if user.Handle == "" {
+		user.Handle, err = app.newHandle(r.Context(), user.Name)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Error creating user"))
+			return
+		}
+	}
 
-	result, err := collection.InsertOne(context.Background(), user)
+	userID, err := app.Users.Create(r.Context(), user)
+	if err == repositories.ErrHandleTaken {
+		apperror.Write(w, apperror.Validation(map[string]string{"handle": handleTakenMessage}))
+		return
+	}
 	if err != nil {
-		http.Error(w, "Error creating user", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Error creating user"))
 		return
 	}
 
-	userID := result.InsertedID.(primitive.ObjectID).Hex()
-	token, err := utils.GenerateJWT(userID, user.Name)
+	user.ID = userID
+
+	// the account works straight away; failing to send the email only means the
+	// user has to ask for another one
+	if err := app.sendAccountToken(r.Context(), user, models.TokenVerifyEmail); err != nil {
+		log.Printf("Could not send verification email: %v", err)
+	}
+
+	tokens, err := app.startSession(w, r, user)
 	if err != nil {
-		http.Error(w, "Error generating token", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Error generating token"))
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "token",
-		Value:    token,
-		HttpOnly: true,
-		Expires:  time.Now().Add(72 * time.Hour),
-		Path:     "/",
-	})
-
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	json.NewEncoder(w).Encode(tokens)
 }
 
 // Login function to logging the user in and generating a token for it
-func Login(w http.ResponseWriter, r *http.Request) {
+func (app *App) Login(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var credentials struct {
@@ -109,63 +140,105 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 	err := json.NewDecoder(r.Body).Decode(&credentials)
 	if err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
 		return
 	}
 
-	collection := config.DB.Collection("users")
-	var user models.User
-	err = collection.FindOne(context.Background(), bson.M{"email": credentials.Email}).Decode(&user)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"email": "Invalid Email"})
+	user, err := app.Users.FindByEmail(r.Context(), credentials.Email)
+	if err != nil && err != repositories.ErrNotFound {
+		apperror.Write(w, apperror.Internal("Error finding user"))
+		return
+	}
+	found := err == nil
+
+	now := time.Now()
+	keys := loginKeys(r, credentials.Email)
+
+	if wait := loginWait(keys, now); wait > 0 {
+		if found {
+			app.recordLogin(r, user.ID, false, models.LoginThrottled)
+		}
+		writeTooManyAttempts(w, wait)
+		return
+	}
+
+	if !found {
+		if locked := unknownEmailLockout.LockedFor(keys[1], now); locked > 0 {
+			failLogin(keys, now)
+			writeTooManyAttempts(w, locked)
 			return
 		}
-		http.Error(w, "Error finding user", http.StatusInternalServerError)
+
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(credentials.Password))
+		failLogin(keys, now)
+		unknownEmailLockout.Fail(keys[1], now)
+		writeInvalidCredentials(w)
+		return
+	}
+
+	// a locked account is refused without checking the password, so guessing gets nowhere
+	if user.LockedUntil > now.Unix() {
+		failLogin(keys, now)
+		app.recordLogin(r, user.ID, false, models.LoginLocked)
+		writeTooManyAttempts(w, time.Unix(user.LockedUntil, 0).Sub(now))
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(credentials.Password))
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"password": "Invalid password"})
+		failLogin(keys, now)
+		app.countFailedLogin(r.Context(), user.ID, now)
+		app.recordLogin(r, user.ID, false, models.LoginInvalidPassword)
+		writeInvalidCredentials(w)
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID.Hex(), user.Name)
-	if err != nil {
-		http.Error(w, "Error generating token", http.StatusInternalServerError)
+	// the IP address is not let off, or one account could be used to keep guessing at others
+	loginBackoff.Reset(keys[1])
+
+	// with 2FA on, the session only starts once a code has been checked by VerifyTwoFactor
+	if user.TwoFactor.Enabled {
+		challengeToken, err := utils.GenerateChallengeToken(user.ID.Hex())
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Error generating token"))
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "token",
-		Value:    token,
-		HttpOnly: true,
-		Expires:  time.Now().Add(72 * time.Hour),
-		Path:     "/",
-	})
+	app.loginSucceeded(r, user)
 
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	tokens, err := app.startSession(w, r, user)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error generating token"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(tokens)
 }
 
-// Logout function to logging out user by removing the cookie
-func Logout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "token",
-		Value:    "",
-		HttpOnly: true,
-		Expires:  time.Now().Add(-1 * time.Hour),
-		Path:     "/",
-	})
+// Logout function to logging out user by revoking their session and removing the cookies
+func (app *App) Logout(w http.ResponseWriter, r *http.Request) {
+	if session, err := app.sessionFromRefreshToken(r, refreshTokenFromRequest(r)); err == nil {
+		if err := app.Sessions.Revoke(r.Context(), session.ID); err != nil {
+			apperror.Write(w, apperror.Internal("Could not end session"))
+			return
+		}
+	}
+
+	clearAuthCookies(w)
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Logged out successfully"))
 }
 
 // ValidateToken function validates if the user is logged in
-func ValidateToken(w http.ResponseWriter, r *http.Request) {
+func (app *App) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	tokenCookie, err := r.Cookie("token")
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,481 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxCollections            = 100
+	maxCollectionItems        = 500
+	maxCollectionNameLength   = 60
+	maxCollectionDescLength   = 280
+	maxCollectionNoteLength   = 500
+	collectionNameMessage     = "Name is required and must be at most 60 characters long."
+	collectionDescMessage     = "Description must be at most 280 characters long."
+	collectionNoteMessage     = "Note must be at most 500 characters long."
+	collectionNotFoundMessage = "Collection not found"
+)
+
+// the shape collections are listed in, without their items
+type collectionSummary struct {
+	models.Collection
+	ItemCount int    `json:"itemCount"`
+	ShareURL  string `json:"shareURL,omitempty"`
+}
+
+type collectionEntry struct {
+	models.CollectionItem
+	Post models.PostSummary `json:"post"`
+}
+
+func summarizeCollection(collection models.Collection) collectionSummary {
+	summary := collectionSummary{Collection: collection, ItemCount: len(collection.Items)}
+	if collection.Public {
+		summary.ShareURL = os.Getenv("FRONTEND_URL") + "/collections/" + collection.ID.Hex()
+	}
+	return summary
+}
+
+// handles listing the signed-in user's collections
+func (app *App) GetMyCollections(w http.ResponseWriter, r *http.Request) {
+	userID, _ := currentUserID(r)
+	app.writeCollections(w, r, userID, false)
+}
+
+// handles listing a user's public collections
+func (app *App) GetUserCollections(w http.ResponseWriter, r *http.Request) {
+	user, err := app.findUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			apperror.Write(w, apperror.New(apperror.UserNotFound, "User not found"))
+			return
+		}
+		apperror.Write(w, apperror.Internal("Could not fetch user"))
+		return
+	}
+
+	app.writeCollections(w, r, user.ID, true)
+}
+
+func (app *App) writeCollections(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, publicOnly bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	collections, err := app.Collections.ListByUser(r.Context(), userID, publicOnly)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch collections"))
+		return
+	}
+
+	response := make([]collectionSummary, 0, len(collections))
+	for _, collection := range collections {
+		response = append(response, summarizeCollection(collection))
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"collections": response})
+}
+
+// handles creating a collection
+func (app *App) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	name, description := strings.TrimSpace(body.Name), strings.TrimSpace(body.Description)
+	if errorMessages := collectionErrors(name, description); len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
+		return
+	}
+
+	userID, _ := currentUserID(r)
+
+	count, err := app.Collections.CountByUser(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not create collection"))
+		return
+	}
+	if count >= maxCollections {
+		apperror.Write(w, apperror.New(apperror.LimitReached, "You have reached the maximum number of collections"))
+		return
+	}
+
+	now := time.Now().Unix()
+	collection := models.Collection{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		Public:      body.Public,
+		Items:       []models.CollectionItem{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := app.Collections.Create(r.Context(), collection); err != nil {
+		apperror.Write(w, apperror.Internal("Could not create collection"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(summarizeCollection(collection))
+}
+
+// handles showing a collection with its posts, to its owner or, when public, to anyone
+func (app *App) GetCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	collectionID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid collection ID"))
+		return
+	}
+
+	collection, err := app.Collections.FindByID(r.Context(), collectionID)
+	if err != nil && err != repositories.ErrNotFound {
+		apperror.Write(w, apperror.Internal("Could not fetch collection"))
+		return
+	}
+
+	// private collections are not found by anyone else, so their IDs give nothing away
+	viewerID, _ := currentUserID(r)
+	if err == repositories.ErrNotFound || (!collection.Public && collection.UserID != viewerID) {
+		apperror.Write(w, apperror.New(apperror.CollectionNotFound, collectionNotFoundMessage))
+		return
+	}
+
+	entries, err := app.collectionEntries(r, collection)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch posts"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		collectionSummary
+		Items []collectionEntry `json:"items"`
+	}{
+		collectionSummary: summarizeCollection(collection),
+		Items:             entries,
+	})
+}
+
+// handles renaming a collection, changing its description or making it public or private
+func (app *App) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Public      *bool   `json:"public"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	if body.Name != nil {
+		collection.Name = strings.TrimSpace(*body.Name)
+	}
+	if body.Description != nil {
+		collection.Description = strings.TrimSpace(*body.Description)
+	}
+	if body.Public != nil {
+		collection.Public = *body.Public
+	}
+
+	if errorMessages := collectionErrors(collection.Name, collection.Description); len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
+		return
+	}
+
+	err := app.Collections.UpdateDetails(r.Context(), collection.ID, collection.Name, collection.Description, collection.Public)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not update collection"))
+		return
+	}
+
+	collection.UpdatedAt = time.Now().Unix()
+	json.NewEncoder(w).Encode(summarizeCollection(collection))
+}
+
+// handles deleting a collection; the posts in it are not affected
+func (app *App) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Collections.Delete(r.Context(), collection.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete collection"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Collection deleted successfully"})
+}
+
+// handles saving a post to the end of a collection
+func (app *App) AddCollectionItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		PostID string `json:"postID"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(body.PostID)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	note := strings.TrimSpace(body.Note)
+	if len(note) > maxCollectionNoteLength {
+		apperror.Write(w, apperror.Validation(map[string]string{"note": collectionNoteMessage}))
+		return
+	}
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	if len(collection.Items) >= maxCollectionItems {
+		apperror.Write(w, apperror.New(apperror.LimitReached, "This collection is full"))
+		return
+	}
+
+	post, err := app.Posts.FindByID(r.Context(), postID)
+	if err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	item := models.CollectionItem{PostID: postID, Note: note, AddedAt: time.Now().Unix()}
+	added, err := app.Collections.AddItem(r.Context(), collection.ID, item)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not save post"))
+		return
+	}
+	if !added {
+		apperror.Write(w, apperror.New(apperror.AlreadyInCollection, "Post is already in this collection"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// handles changing the note on a saved post
+func (app *App) UpdateCollectionItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	note := strings.TrimSpace(body.Note)
+	if len(note) > maxCollectionNoteLength {
+		apperror.Write(w, apperror.Validation(map[string]string{"note": collectionNoteMessage}))
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["postId"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := app.Collections.SetItemNote(r.Context(), collection.ID, postID, note)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not update note"))
+		return
+	}
+	if !found {
+		apperror.Write(w, apperror.New(apperror.CollectionItemNotFound, "Post is not in this collection"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"note": note})
+}
+
+// handles taking a post out of a collection
+func (app *App) RemoveCollectionItem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["postId"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := app.Collections.RemoveItem(r.Context(), collection.ID, postID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not remove post"))
+		return
+	}
+	if !removed {
+		apperror.Write(w, apperror.New(apperror.CollectionItemNotFound, "Post is not in this collection"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Post removed from collection"})
+}
+
+// handles reordering a collection; the body must list every post in it exactly once
+func (app *App) ReorderCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		PostIDs []string `json:"postIDs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	collection, ok := app.ownCollection(w, r)
+	if !ok {
+		return
+	}
+
+	items := make(map[primitive.ObjectID]models.CollectionItem, len(collection.Items))
+	for _, item := range collection.Items {
+		items[item.PostID] = item
+	}
+
+	ordered := make([]models.CollectionItem, 0, len(body.PostIDs))
+	for _, value := range body.PostIDs {
+		postID, err := primitive.ObjectIDFromHex(value)
+		item, ok := items[postID]
+		if err != nil || !ok {
+			apperror.Write(w, apperror.New(apperror.InvalidInput, "postIDs must list every post in the collection exactly once"))
+			return
+		}
+		ordered = append(ordered, item)
+		delete(items, postID)
+	}
+	if len(items) > 0 {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "postIDs must list every post in the collection exactly once"))
+		return
+	}
+
+	if err := app.Collections.SetItems(r.Context(), collection.ID, ordered); err != nil {
+		apperror.Write(w, apperror.Internal("Could not reorder collection"))
+		return
+	}
+
+	collection.Items = ordered
+	entries, err := app.collectionEntries(r, collection)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch posts"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"items": entries})
+}
+
+// loads the collection named in the URL, writing a 404 unless it belongs to the signed-in user
+func (app *App) ownCollection(w http.ResponseWriter, r *http.Request) (models.Collection, bool) {
+	collectionID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid collection ID"))
+		return models.Collection{}, false
+	}
+
+	collection, err := app.Collections.FindByID(r.Context(), collectionID)
+	if err != nil && err != repositories.ErrNotFound {
+		apperror.Write(w, apperror.Internal("Could not fetch collection"))
+		return models.Collection{}, false
+	}
+
+	userID, _ := currentUserID(r)
+	if err == repositories.ErrNotFound || collection.UserID != userID {
+		apperror.Write(w, apperror.New(apperror.CollectionNotFound, collectionNotFoundMessage))
+		return models.Collection{}, false
+	}
+	return collection, true
+}
+
+// pairs each item with its post, in the collection's order; posts that have
+// since been hidden are left out
+func (app *App) collectionEntries(r *http.Request, collection models.Collection) ([]collectionEntry, error) {
+	postIDs := make([]primitive.ObjectID, len(collection.Items))
+	for i, item := range collection.Items {
+		postIDs[i] = item.PostID
+	}
+
+	summaries, err := app.Posts.Summaries(r.Context(), postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]models.PostSummary, 0, len(summaries))
+	for _, item := range collection.Items {
+		if summary, ok := summaries[item.PostID]; ok {
+			posts = append(posts, summary)
+		}
+	}
+	if err := app.personalize(r, posts); err != nil {
+		return nil, err
+	}
+
+	entries := make([]collectionEntry, 0, len(posts))
+	for _, item := range collection.Items {
+		if _, ok := summaries[item.PostID]; ok {
+			entries = append(entries, collectionEntry{CollectionItem: item, Post: posts[len(entries)]})
+		}
+	}
+	return entries, nil
+}
+
+func collectionErrors(name, description string) map[string]string {
+	errorMessages := make(map[string]string)
+	if name == "" || len(name) > maxCollectionNameLength {
+		errorMessages["name"] = collectionNameMessage
+	}
+	if len(description) > maxCollectionDescLength {
+		errorMessages["description"] = collectionDescMessage
+	}
+	return errorMessages
+}
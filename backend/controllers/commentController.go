@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handles fetching a page of a post's comment threads
+func (app *App) GetComments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	// comments go with their post when it is hidden or deleted
+	if post, err := app.Posts.FindByID(r.Context(), postID); err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	query, err := parseListQuery(r, repositories.SortNewest)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	page, err := app.Comments.ListThreads(r.Context(), postID, query.Limit, query.After)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch comments"))
+		return
+	}
+
+	var nextCursor interface{}
+	if page.Next != nil {
+		nextCursor = encodeCursor(page.Next)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"comments":   page.Threads,
+		"total":      page.Total,
+		"nextCursor": nextCursor,
+	})
+}
+
+// handles adding a comment, or a reply when ParentID is given, to a specific post
+func (app *App) AddComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var comment models.Comment
+
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid comment data"))
+		return
+	}
+
+	comment.Text = strings.TrimSpace(comment.Text)
+	if err := validate.Struct(comment); err != nil {
+		apperror.Write(w, apperror.Validation(fieldErrors(err)))
+		return
+	}
+
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
+		return
+	}
+
+	enduserID, _ := primitive.ObjectIDFromHex(userID)
+
+	user, err := app.Users.FindByID(r.Context(), enduserID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("User not found"))
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	if post, err := app.Posts.FindByID(r.Context(), postID); err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	comment.ID = primitive.NewObjectID()
+	comment.PostID = postID
+	comment.RootID = comment.ID
+	comment.UserID = enduserID
+	comment.Name = user.Name
+	comment.Created = time.Now().Unix()
+	comment.UpdatedAt = 0
+	comment.Deleted = false
+	comment.Hidden = false
+
+	if comment.ParentID != nil {
+		parent, err := app.Comments.FindByID(r.Context(), *comment.ParentID)
+		if err != nil || parent.PostID != postID {
+			apperror.Write(w, apperror.New(apperror.CommentNotFound, "Parent comment not found"))
+			return
+		}
+		if parent.Deleted {
+			apperror.Write(w, apperror.New(apperror.CommentDeleted, "Cannot reply to a deleted comment"))
+			return
+		}
+		comment.RootID = parent.RootID
+	}
+
+	err = app.Comments.Create(r.Context(), comment)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not add comment"))
+		return
+	}
+
+	if err := app.refreshCommentCount(r, postID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment count"))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// handles editing the text of a comment
+func (app *App) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var update struct {
+		Text string `validate:"required,max=2000"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid comment data"))
+		return
+	}
+
+	update.Text = strings.TrimSpace(update.Text)
+	if err := validate.Struct(update); err != nil {
+		apperror.Write(w, apperror.Validation(fieldErrors(err)))
+		return
+	}
+
+	comment, ok := app.authorizeComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Comments.UpdateText(r.Context(), comment.ID, update.Text); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment"))
+		return
+	}
+
+	comment, err := app.Comments.FindByID(r.Context(), comment.ID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch comment"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(comment)
+}
+
+// handles deleting a comment, leaving a placeholder so that its replies keep their place
+func (app *App) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	comment, ok := app.authorizeComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Comments.SoftDelete(r.Context(), comment.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete comment"))
+		return
+	}
+
+	if err := app.refreshCommentCount(r, comment.PostID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update comment count"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Comment deleted successfully"})
+}
+
+// loads the comment named in the URL and checks that the caller wrote it or owns
+// the post it was left on, writing the error response when they may not change it
+func (app *App) authorizeComment(w http.ResponseWriter, r *http.Request) (models.Comment, bool) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
+		return models.Comment{}, false
+	}
+	enduserID, _ := primitive.ObjectIDFromHex(userID)
+
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return models.Comment{}, false
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(mux.Vars(r)["commentId"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid comment ID"))
+		return models.Comment{}, false
+	}
+
+	comment, err := app.Comments.FindByID(r.Context(), commentID)
+	if err != nil || comment.PostID != postID || comment.Deleted {
+		apperror.Write(w, apperror.New(apperror.CommentNotFound, "Comment not found"))
+		return models.Comment{}, false
+	}
+
+	if comment.UserID == enduserID {
+		return comment, true
+	}
+
+	post, err := app.Posts.FindByID(r.Context(), postID)
+	if err != nil || post.UserID != enduserID {
+		apperror.Write(w, apperror.New(apperror.Forbidden, "You can only change your own comments or comments on your posts"))
+		return models.Comment{}, false
+	}
+
+	return comment, true
+}
+
+// the count is recomputed rather than incremented so it cannot drift
+func (app *App) refreshCommentCount(r *http.Request, postID primitive.ObjectID) error {
+	count, err := app.Comments.CountByPost(r.Context(), postID)
+	if err != nil {
+		return err
+	}
+	return app.Posts.SetCommentCount(r.Context(), postID, count)
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feeds say which posts they are made of, so clients can explain an empty following list
+const (
+	feedFollowing = "following"
+	feedTrending  = "trending"
+)
+
+// handles following a cook
+func (app *App) FollowUser(w http.ResponseWriter, r *http.Request) {
+	app.setUserFollow(w, r, true)
+}
+
+// handles unfollowing a cook
+func (app *App) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	app.setUserFollow(w, r, false)
+}
+
+func (app *App) setUserFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	followerID, _ := currentUserID(r)
+
+	user, err := app.findUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			apperror.Write(w, apperror.New(apperror.UserNotFound, "User not found"))
+			return
+		}
+		apperror.Write(w, apperror.Internal("Could not fetch user"))
+		return
+	}
+
+	if user.ID == followerID {
+		apperror.Write(w, apperror.New(apperror.CannotFollowSelf, "You cannot follow yourself"))
+		return
+	}
+
+	app.setFollow(w, r, followerID, models.FollowUser, user.ID.Hex(), follow)
+}
+
+// handles following a country, whose posts then show up in the feed
+func (app *App) FollowCountry(w http.ResponseWriter, r *http.Request) {
+	app.setCountryFollow(w, r, true)
+}
+
+// handles unfollowing a country
+func (app *App) UnfollowCountry(w http.ResponseWriter, r *http.Request) {
+	app.setCountryFollow(w, r, false)
+}
+
+func (app *App) setCountryFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	followerID, _ := currentUserID(r)
+
+	country := strings.TrimSpace(mux.Vars(r)["country"])
+	if country == "" || len(country) > maxCountryLength {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid country"))
+		return
+	}
+	// names the list doesn't know are still accepted, so that follows made
+	// before posts were checked against it can be removed
+	if canonical, ok := models.CanonicalCountry(country); ok {
+		country = canonical
+	}
+
+	app.setFollow(w, r, followerID, models.FollowCountry, country, follow)
+}
+
+// adds or removes the follow and responds with the target's new follower count
+func (app *App) setFollow(w http.ResponseWriter, r *http.Request, followerID primitive.ObjectID, kind, target string, follow bool) {
+	var err error
+	if follow {
+		err = app.Follows.Add(r.Context(), models.Follow{
+			FollowerID: followerID,
+			Kind:       kind,
+			Target:     target,
+			CreatedAt:  time.Now().Unix(),
+		})
+	} else {
+		_, err = app.Follows.Remove(r.Context(), followerID, kind, target)
+	}
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not update follow"))
+		return
+	}
+
+	followers, err := app.Follows.CountFollowers(r.Context(), kind, target)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not count followers"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"following": follow,
+		"followers": followers,
+	})
+}
+
+// handles listing the cooks and countries the signed-in user follows
+func (app *App) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	follows, err := app.Follows.ListByFollower(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch follows"))
+		return
+	}
+
+	type followedUser struct {
+		ID     primitive.ObjectID `json:"id"`
+		Name   string             `json:"name"`
+		Handle string             `json:"handle"`
+		Avatar string             `json:"avatar"`
+	}
+
+	countries := []string{}
+	userIDs := []primitive.ObjectID{}
+	for _, follow := range follows {
+		if follow.Kind == models.FollowCountry {
+			countries = append(countries, follow.Target)
+			continue
+		}
+		if objectID, err := primitive.ObjectIDFromHex(follow.Target); err == nil {
+			userIDs = append(userIDs, objectID)
+		}
+	}
+
+	found, err := app.Users.FindByIDs(r.Context(), userIDs)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch follows"))
+		return
+	}
+	byID := make(map[primitive.ObjectID]models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	// kept in the order of the follows; cooks whose accounts are gone are left out
+	users := []followedUser{}
+	for _, id := range userIDs {
+		if user, ok := byID[id]; ok {
+			users = append(users, followedUser{ID: user.ID, Name: user.Name, Handle: user.Handle, Avatar: user.Avatar})
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users":     users,
+		"countries": countries,
+	})
+}
+
+// handles the home feed: recent posts from followed cooks and countries, or
+// trending posts for users whose follows have not posted anything yet
+func (app *App) GetFeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	query, err := parseListQuery(r, repositories.SortNewest)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	follows, err := app.Follows.ListByFollower(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch follows"))
+		return
+	}
+
+	for _, follow := range follows {
+		if follow.Kind == models.FollowCountry {
+			query.FollowedCountries = append(query.FollowedCountries, follow.Target)
+		} else if authorID, err := primitive.ObjectIDFromHex(follow.Target); err == nil {
+			query.FollowedAuthors = append(query.FollowedAuthors, authorID)
+		}
+	}
+
+	source := feedFollowing
+	var page repositories.PostPage
+	if len(query.FollowedAuthors) > 0 || len(query.FollowedCountries) > 0 {
+		page, err = app.Posts.List(r.Context(), query)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not fetch feed"))
+			return
+		}
+	}
+
+	// the choice only depends on the user's follows, so every page of a feed comes from the same source
+	if page.Total == 0 {
+		source = feedTrending
+		query.FollowedAuthors = nil
+		query.FollowedCountries = nil
+		if r.URL.Query().Get("sort") == "" {
+			query.Sort = repositories.SortTrending
+		}
+
+		page, err = app.Posts.List(r.Context(), query)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not fetch feed"))
+			return
+		}
+	}
+
+	if err := app.personalize(r, page.Posts); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
+	}
+
+	response := listResponse(page)
+	response["source"] = source
+	json.NewEncoder(w).Encode(response)
+}
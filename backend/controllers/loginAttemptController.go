@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// each IP address and email gets this many failures before it has to wait
+	loginFreeAttempts = 5
+	loginBackoffBase  = time.Second
+	loginBackoffMax   = 15 * time.Minute
+
+	// an account is locked after this many failures in a row, however they were spread out
+	maxFailedLogins = 10
+	lockoutDuration = 15 * time.Minute
+
+	loginHistoryLimit = 50
+
+	invalidCredentialsMessage = "Invalid email or password"
+	tooManyAttemptsMessage    = "Too many login attempts, please try again later"
+)
+
+var loginBackoff = utils.NewBackoff(loginFreeAttempts, loginBackoffBase, loginBackoffMax)
+
+// emails without an account are locked out just as accounts are, or the lockout
+// would tell which emails have one
+var unknownEmailLockout = utils.NewLockout(maxFailedLogins, lockoutDuration)
+
+// compared against when the email is unknown, so that answering takes as long as for a wrong password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("urcuisine-dummy-password"), bcrypt.DefaultCost)
+
+// the rate limiter keys for a login attempt
+func loginKeys(r *http.Request, email string) []string {
+	return []string{"ip:" + clientIP(r), "email:" + strings.ToLower(strings.TrimSpace(email))}
+}
+
+// how long until all of the keys may try again
+func loginWait(keys []string, now time.Time) time.Duration {
+	var wait time.Duration
+	for _, key := range keys {
+		if keyWait := loginBackoff.Wait(key, now); keyWait > wait {
+			wait = keyWait
+		}
+	}
+	return wait
+}
+
+func failLogin(keys []string, now time.Time) {
+	for _, key := range keys {
+		loginBackoff.Fail(key, now)
+	}
+}
+
+// the same answer is given for an unknown email and a wrong password, so it
+// cannot be used to find out who has an account
+func writeInvalidCredentials(w http.ResponseWriter) {
+	apperror.Write(w, apperror.New(apperror.InvalidCredentials, invalidCredentialsMessage))
+}
+
+func writeTooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	apperror.Write(w, apperror.New(apperror.TooManyAttempts, tooManyAttemptsMessage))
+}
+
+// counts a failed sign-in against the account, locking it once there have been too many in a row
+func (app *App) countFailedLogin(ctx context.Context, userID primitive.ObjectID, now time.Time) {
+	failures, err := app.Users.RecordFailedLogin(ctx, userID)
+	if err != nil {
+		log.Printf("Could not record failed login: %v", err)
+		return
+	}
+
+	if failures >= maxFailedLogins {
+		if err := app.Users.LockUntil(ctx, userID, now.Add(lockoutDuration).Unix()); err != nil {
+			log.Printf("Could not lock account: %v", err)
+		}
+	}
+}
+
+// records a successful sign-in, clearing the failures counted against the account
+func (app *App) loginSucceeded(r *http.Request, user models.User) {
+	if user.FailedLogins > 0 || user.LockedUntil > 0 {
+		if err := app.Users.ResetFailedLogins(r.Context(), user.ID); err != nil {
+			log.Printf("Could not reset failed logins: %v", err)
+		}
+	}
+	app.recordLogin(r, user.ID, true, "")
+}
+
+// adds an attempt to the user's login history; failing to do so does not stop the login
+func (app *App) recordLogin(r *http.Request, userID primitive.ObjectID, success bool, reason string) {
+	err := app.LoginEvents.Create(r.Context(), models.LoginEvent{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Success:   success,
+		Reason:    reason,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Printf("Could not record login: %v", err)
+	}
+}
+
+// GetLoginHistory lists the recent attempts to sign in to the user's account
+func (app *App) GetLoginHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := currentUserID(r)
+
+	events, err := app.LoginEvents.ListByUser(r.Context(), userID, loginHistoryLimit)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch login history"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"events": events})
+}
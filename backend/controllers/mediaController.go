@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/images"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxImageSize = 10 << 20
+	maxVideoSize = 50 << 20
+	// room for the multipart boundaries and headers around the file
+	maxUploadOverhead = 1 << 20
+)
+
+type mediaType struct {
+	kind    string
+	ext     string
+	maxSize int64
+}
+
+// the types uploads may have, by the content type sniffed from their first bytes
+var mediaTypes = map[string]mediaType{
+	"image/jpeg": {models.MediaImage, ".jpg", maxImageSize},
+	"image/png":  {models.MediaImage, ".png", maxImageSize},
+	"image/webp": {models.MediaImage, ".webp", maxImageSize},
+	"image/gif":  {models.MediaImage, ".gif", maxImageSize},
+	"video/mp4":  {models.MediaVideo, ".mp4", maxVideoSize},
+	"video/webm": {models.MediaVideo, ".webm", maxVideoSize},
+}
+
+// UploadMedia stores the image or video sent as the "file" field of a multipart
+// form, to be attached to the caller's posts by its ID
+func (app *App) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := currentUserID(r)
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxVideoSize+maxUploadOverhead)
+	if err := r.ParseMultipartForm(maxImageSize); err != nil {
+		if _, tooLarge := err.(*http.MaxBytesError); tooLarge {
+			apperror.Write(w, apperror.New(apperror.FileTooLarge, "File is too large"))
+			return
+		}
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid multipart form"))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		apperror.Write(w, apperror.Validation(map[string]string{"file": "This field is required."}))
+		return
+	}
+	defer file.Close()
+
+	// the declared content type is up to the client, so it is taken from the file itself
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Could not read file"))
+		return
+	}
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	typ, ok := mediaTypes[contentType]
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.UnsupportedMediaType, "Unsupported file type, upload a JPEG, PNG, WebP or GIF image or an MP4 or WebM video"))
+		return
+	}
+	if header.Size > typ.maxSize {
+		apperror.Write(w, apperror.New(apperror.FileTooLarge, fmt.Sprintf("File is too large, %ss may be up to %d MB", typ.kind, typ.maxSize>>20)))
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		apperror.Write(w, apperror.Internal("Could not read file"))
+		return
+	}
+
+	media := models.Media{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Kind:        typ.kind,
+		ContentType: contentType,
+		Size:        header.Size,
+		CreatedAt:   time.Now().Unix(),
+	}
+	media.Key = userID.Hex() + "/" + media.ID.Hex() + typ.ext
+	media.URL = app.Blobs.URL(media.Key)
+
+	var body io.Reader = file
+	if typ.kind == models.MediaImage {
+		// photos can tell where they were taken, which is not for everyone to see
+		data, err := io.ReadAll(file)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not read file"))
+			return
+		}
+		data, err = images.StripMetadata(data, contentType)
+		if err != nil {
+			apperror.Write(w, apperror.New(apperror.InvalidInput, "Could not read image"))
+			return
+		}
+		body, media.Size = bytes.NewReader(data), int64(len(data))
+		media.Status = models.MediaPending
+	}
+
+	if err := app.Blobs.Put(r.Context(), media.Key, body, media.Size, contentType); err != nil {
+		apperror.Write(w, apperror.Internal("Could not store file"))
+		return
+	}
+
+	if err := app.Media.Create(r.Context(), media); err != nil {
+		app.Blobs.Delete(r.Context(), media.Key)
+		apperror.Write(w, apperror.Internal("Could not save media"))
+		return
+	}
+
+	if media.Status == models.MediaPending {
+		app.Images.Enqueue(media)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(media)
+}
+
+// fills in the attached media from the user's uploads, returning the field errors
+// for entries that are not theirs or are listed twice
+func (app *App) resolveMedia(ctx context.Context, userID primitive.ObjectID, attached []models.PostMedia) (map[string]string, error) {
+	errors := make(map[string]string)
+	seen := make(map[primitive.ObjectID]bool)
+
+	for i := range attached {
+		field := fmt.Sprintf("media[%d].id", i)
+		item := &attached[i]
+
+		if seen[item.ID] {
+			errors[field] = "This media is already attached."
+			continue
+		}
+		seen[item.ID] = true
+
+		media, err := app.Media.FindByID(ctx, item.ID)
+		if err == repositories.ErrNotFound || (err == nil && media.UserID != userID) {
+			errors[field] = "Media not found."
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item.Kind = media.Kind
+		item.URL = media.URL
+		item.ContentType = media.ContentType
+		item.ImageDetails = media.ImageDetails
+		item.Alt = strings.TrimSpace(item.Alt)
+	}
+
+	return errors, nil
+}
+
+// copies the details of attached images onto the saved post when they were
+// processed after resolveMedia read them. The pipeline copies them onto posts
+// too, but not onto one that was saved after it did
+func (app *App) refreshMediaDetails(ctx context.Context, post *models.Post) error {
+	for i := range post.Media {
+		item := &post.Media[i]
+		media, err := app.Media.FindByID(ctx, item.ID)
+		if err == repositories.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if media.Status != models.MediaReady || reflect.DeepEqual(media.ImageDetails, item.ImageDetails) {
+			continue
+		}
+
+		if err := app.Posts.SetMediaDetails(ctx, item.ID, media.ImageDetails); err != nil {
+			return err
+		}
+		item.ImageDetails = media.ImageDetails
+	}
+	return nil
+}
+
+// resolves the media attached to a post, writing the error response when any can't be attached
+func (app *App) attachMedia(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, attached []models.PostMedia) bool {
+	errors, err := app.resolveMedia(r.Context(), userID, attached)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch media"))
+		return false
+	}
+	if len(errors) > 0 {
+		apperror.Write(w, apperror.Validation(errors))
+		return false
+	}
+	return true
+}
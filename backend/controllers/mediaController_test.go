@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the pipeline finishes after resolveMedia read the upload but before the post is saved
+func TestRefreshMediaDetails(t *testing.T) {
+	ctx := context.Background()
+	users := repositories.NewMemoryUserRepository()
+	app := &App{
+		Posts: repositories.NewMemoryPostRepository(users),
+		Media: repositories.NewMemoryMediaRepository(),
+	}
+
+	media := models.Media{ID: primitive.NewObjectID(), Kind: models.MediaImage, Status: models.MediaPending}
+	if err := app.Media.Create(ctx, media); err != nil {
+		t.Fatal(err)
+	}
+	post := models.Post{ID: primitive.NewObjectID(), Title: "Karahi", Media: []models.PostMedia{{ID: media.ID, Kind: media.Kind}}}
+
+	details := models.ImageDetails{Width: 800, Height: 600, Blurhash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH"}
+	if err := app.Media.SetImageDetails(ctx, media.ID, models.MediaReady, details); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Posts.SetMediaDetails(ctx, media.ID, details); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Posts.Create(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.refreshMediaDetails(ctx, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := app.Posts.FindByID(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Media[0].Blurhash != details.Blurhash || post.Media[0].Width != details.Width {
+		t.Errorf("details were not copied: saved %+v, returned %+v", saved.Media[0].ImageDetails, post.Media[0].ImageDetails)
+	}
+}
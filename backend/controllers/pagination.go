@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 50
+)
+
+// posts are stored under the ISO name, so "USA" and "us" find them too; a
+// name that is no country is kept, and finds nothing
+func countryFilter(name string) string {
+	if country, ok := models.CanonicalCountry(name); ok {
+		return country
+	}
+	return name
+}
+
+// reads the limit, after, sort and filter query parameters shared by every post listing
+func parseListQuery(r *http.Request, defaultSort string) (repositories.PostQuery, error) {
+	params := r.URL.Query()
+	query := repositories.PostQuery{
+		Country:    countryFilter(params.Get("country")),
+		Ingredient: params.Get("ingredient"),
+		Sort:       defaultSort,
+		Limit:      defaultListLimit,
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return query, fmt.Errorf("Invalid limit parameter")
+		}
+		query.Limit = limit
+	}
+	if query.Limit > maxListLimit {
+		query.Limit = maxListLimit
+	}
+
+	if value := params.Get("sort"); value != "" {
+		switch value {
+		case repositories.SortNewest, repositories.SortMostLiked, repositories.SortMostCommented, repositories.SortTrending:
+			query.Sort = value
+		default:
+			return query, fmt.Errorf("Sort must be one of newest, most_liked, most_commented or trending")
+		}
+	}
+
+	if value := params.Get("author"); value != "" {
+		authorID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return query, fmt.Errorf("Invalid author parameter")
+		}
+		query.AuthorID = authorID
+	}
+
+	// dates are whole days, and "to" includes the day it names
+	if value := params.Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return query, fmt.Errorf("Invalid from parameter, expected YYYY-MM-DD")
+		}
+		query.From = from.Unix()
+	}
+	if value := params.Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return query, fmt.Errorf("Invalid to parameter, expected YYYY-MM-DD")
+		}
+		query.To = to.AddDate(0, 0, 1).Unix()
+	}
+
+	if value := params.Get("after"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err != nil {
+			return query, fmt.Errorf("Invalid after parameter")
+		}
+		query.After = cursor
+	}
+
+	return query, nil
+}
+
+func encodeCursor(cursor *repositories.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*repositories.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor repositories.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// wraps a page of results in the envelope every listing endpoint responds with
+func listResponse(page repositories.PostPage) map[string]interface{} {
+	var nextCursor interface{}
+	if page.Next != nil {
+		nextCursor = encodeCursor(page.Next)
+	}
+
+	return map[string]interface{}{
+		"posts":      page.Posts,
+		"total":      page.Total,
+		"nextCursor": nextCursor,
+	}
+}
@@ -1,484 +1,424 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func getUserIDFromToken(r *http.Request) (string, error) {
-	cookie, err := r.Cookie("token")
-	if err != nil {
-		return "", fmt.Errorf("could not find token in cookies")
-	}
-
-	token, _, err := new(jwt.Parser).ParseUnverified(cookie.Value, jwt.MapClaims{})
-	if err != nil {
-		return "", fmt.Errorf("error decoding token: %v", err)
-	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if userID, ok := claims["userID"].(string); ok {
-			return userID, nil
-		}
-	}
-
-	return "", fmt.Errorf("user ID not found in token claims")
-}
-
 // handles creating a new post
-func CreatePost(w http.ResponseWriter, r *http.Request) {
+func (app *App) CreatePost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	userID, ok := r.Context().Value("userID").(string)
 
 	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
 		return
 	}
 
-	var post models.Post
+	// counters, moderation and timestamps are set here, never taken from the body
+	var body struct {
+		Title       string             `json:"title"`
+		Description string             `json:"description"`
+		VideoURL    string             `json:"video_url"`
+		Media       []models.PostMedia `json:"media"`
+		Recipe      models.Recipe      `json:"recipe"`
+		Country     string             `json:"country"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	post := models.Post{
+		Title:       body.Title,
+		Description: body.Description,
+		VideoURL:    body.VideoURL,
+		Media:       body.Media,
+		Recipe:      body.Recipe,
+		Country:     body.Country,
+	}
+	if errorMessages := validatePost(&post); errorMessages != nil {
+		apperror.Write(w, apperror.Validation(errorMessages))
 		return
 	}
 
 	post.ID = primitive.NewObjectID()
 	post.UserID, _ = primitive.ObjectIDFromHex(userID)
-	post.Likes = 0
-	post.Dislikes = 0
-	post.Comments = []models.Comment{}
-	post.CreatedAt = time.Now().Unix()
 
-	postCollection := config.DB.Collection("posts")
+	if !app.attachMedia(w, r, post.UserID, post.Media) {
+		return
+	}
+
+	// the author's name is stored on the post so that it can be searched
+	user, err := app.Users.FindByID(r.Context(), post.UserID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("User not found"))
+		return
+	}
+	post.AuthorName = user.Name
+
+	post.CreatedAt = time.Now().Unix()
 
-	_, err := postCollection.InsertOne(context.Background(), post)
+	err = app.Posts.Create(r.Context(), post)
 	if err != nil {
-		http.Error(w, "Could not create post", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Could not create post"))
 		return
 	}
 
+	// the post is saved either way; its images show as pending until this works
+	if err := app.refreshMediaDetails(r.Context(), &post); err != nil {
+		log.Printf("Could not refresh media of post %s: %v", post.ID.Hex(), err)
+	}
+
 	json.NewEncoder(w).Encode(post)
 }
 
-// handles fetching all posts
-func GetPosts(w http.ResponseWriter, r *http.Request) {
+// handles listing posts, trending first unless another sort is requested
+func (app *App) GetPosts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var posts []models.Post
-	postCollection := config.DB.Collection("posts")
-
-	pipeline := mongo.Pipeline{
-		{{"$match", bson.M{"likes": bson.M{"$gt": 0}}}},
-		{{"$sample", bson.M{"size": 10}}},
+	query, err := parseListQuery(r, repositories.SortTrending)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
 	}
 
-	cursor, err := postCollection.Aggregate(context.Background(), pipeline)
+	page, err := app.Posts.List(r.Context(), query)
 	if err != nil {
-		http.Error(w, "Could not fetch posts", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Could not fetch posts"))
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	for cursor.Next(context.Background()) {
-		var post models.Post
-		if err := cursor.Decode(&post); err != nil {
-			http.Error(w, "Error decoding post", http.StatusInternalServerError)
-			return
-		}
-		posts = append(posts, post)
+	if err := app.personalize(r, page.Posts); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
 	}
 
-	json.NewEncoder(w).Encode(posts)
+	json.NewEncoder(w).Encode(listResponse(page))
 }
 
-func GetPost(w http.ResponseWriter, r *http.Request) {
+func (app *App) GetPost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Get the post ID from the URL parameters
 	postID := mux.Vars(r)["id"]
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
 		return
 	}
 
-	postCollection := config.DB.Collection("posts")
-
-	var post models.Post
-	err = postCollection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
-	if err != nil {
-		http.Error(w, "Post not found", http.StatusNotFound)
+	post, err := app.Posts.FindByID(r.Context(), objectID)
+	if err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
 		return
 	}
 
-	userID, err := getUserIDFromToken(r)
-	if err == nil {
-		enduserID, _ := primitive.ObjectIDFromHex(userID)
-		userCollection := config.DB.Collection("users")
-
-		var user models.User
-		err := userCollection.FindOne(context.Background(), bson.M{"_id": enduserID}).Decode(&user)
-		if err == nil {
-			response := struct {
-				Post        models.Post          `json:"post"`
-				LikesList   []primitive.ObjectID `json:"likesList"`
-				DislikeList []primitive.ObjectID `json:"dislikeList"`
-			}{
-				Post:        post,
-				LikesList:   user.LikesList,
-				DislikeList: user.DislikeList,
-			}
-
-			json.NewEncoder(w).Encode(response)
+	// signed-in users also get the reactions they left, known only from a verified token
+	if enduserID, ok := currentUserID(r); ok {
+		kinds, err := app.Reactions.KindsByUser(r.Context(), enduserID, objectID)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not fetch reactions"))
 			return
 		}
+
+		response := struct {
+			Post        models.Post `json:"post"`
+			MyReactions []string    `json:"myReactions"`
+		}{
+			Post:        post,
+			MyReactions: kinds,
+		}
+
+		json.NewEncoder(w).Encode(response)
+		return
 	}
 
 	json.NewEncoder(w).Encode(post)
 }
 
-func GetPostsByCountry(w http.ResponseWriter, r *http.Request) {
+func (app *App) GetPostsByCountry(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	country := mux.Vars(r)["country"]
-
-	postCollection := config.DB.Collection("posts")
-	userCollection := config.DB.Collection("users")
-
-	cursor, err := postCollection.Find(context.Background(), bson.M{"country": country})
+	query, err := parseListQuery(r, repositories.SortNewest)
 	if err != nil {
-		http.Error(w, "Could not fetch posts", http.StatusInternalServerError)
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
 		return
 	}
-	defer cursor.Close(context.Background())
-
-	var postsWithUserInfo []map[string]interface{}
-
-	for cursor.Next(context.Background()) {
-		var post models.Post
-		if err := cursor.Decode(&post); err != nil {
-			http.Error(w, "Error decoding post", http.StatusInternalServerError)
-			return
-		}
+	query.Country = countryFilter(mux.Vars(r)["country"])
 
-		var user models.User
-		err := userCollection.FindOne(context.Background(), bson.M{"_id": post.UserID}).Decode(&user)
-		if err != nil {
-			http.Error(w, "Could not fetch user details", http.StatusInternalServerError)
-			return
-		}
+	page, err := app.Posts.List(r.Context(), query)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch posts"))
+		return
+	}
 
-		postWithUser := map[string]interface{}{
-			"ID":        post.ID,
-			"Title":     post.Title,
-			"UserName":  user.Name,
-			"Country":   post.Country,
-			"CreatedAt": post.CreatedAt,
-		}
-		postsWithUserInfo = append(postsWithUserInfo, postWithUser)
+	if err := app.personalize(r, page.Posts); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
 	}
 
-	json.NewEncoder(w).Encode(postsWithUserInfo)
+	json.NewEncoder(w).Encode(listResponse(page))
 }
 
 // handles fetching related dishes based on the post's country
-func GetRelatedPosts(w http.ResponseWriter, r *http.Request) {
+func (app *App) GetRelatedPosts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	postID := mux.Vars(r)["id"] // post ID from the URL parameters
 
 	objectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
 		return
 	}
 
-	postCollection := config.DB.Collection("posts")
-	var post models.Post
-	err = postCollection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	query, err := parseListQuery(r, repositories.SortMostLiked)
 	if err != nil {
-		http.Error(w, "Post not found", http.StatusNotFound)
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	post, err := app.Posts.FindByID(r.Context(), objectID)
+	if err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
 		return
 	}
 
-	var relatedPosts []models.Post
-	cursor, err := postCollection.Find(context.Background(), bson.M{
-		"country": post.Country,
-		"_id":     bson.M{"$ne": post.ID}, // this removes the current post
-	})
+	query.Country = post.Country
+	query.ExcludeID = post.ID // this removes the current post
+
+	page, err := app.Posts.List(r.Context(), query)
 	if err != nil {
-		http.Error(w, "Could not fetch related posts", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Could not fetch related posts"))
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	for cursor.Next(context.Background()) {
-		var relatedPost models.Post
-		cursor.Decode(&relatedPost)
-		relatedPosts = append(relatedPosts, relatedPost)
+	if err := app.personalize(r, page.Posts); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
 	}
 
-	json.NewEncoder(w).Encode(relatedPosts)
+	json.NewEncoder(w).Encode(listResponse(page))
 }
 
-// handles updating a specific post
-func UpdatePost(w http.ResponseWriter, r *http.Request) {
+// handles PATCH requests changing a post; only the fields given are changed
+// and checked, and the updated post is returned
+func (app *App) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	userID, ok := r.Context().Value("userID").(string)
+	var body struct {
+		Title       *string             `json:"title"`
+		Description *string             `json:"description"`
+		VideoURL    *string             `json:"video_url"`
+		Media       *[]models.PostMedia `json:"media"`
+		Recipe      *models.Recipe      `json:"recipe"`
+		Country     *string             `json:"country"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
 
+	post, ok := app.ownPost(w, r)
 	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
 		return
 	}
 
-	var updateData models.Post
-	_ = json.NewDecoder(r.Body).Decode(&updateData)
-
-	objectID, _ := primitive.ObjectIDFromHex(userID)
-	updateData.UserID = objectID
-	filter := bson.M{"_id": updateData.ID, "userID": objectID}
-	update := bson.M{
-		"$set": bson.M{
-			"title":       updateData.Title,
-			"description": updateData.Description,
-			"recipe":      updateData.Recipe,
-			"updatedAt":   time.Now().Unix(),
-		},
+	sent := make(map[string]bool)
+	if body.Title != nil {
+		post.Title = *body.Title
+		sent["title"] = true
+	}
+	if body.Description != nil {
+		post.Description = *body.Description
+		sent["description"] = true
+	}
+	if body.VideoURL != nil {
+		post.VideoURL = *body.VideoURL
+		sent["video_url"] = true
+	}
+	if body.Recipe != nil {
+		post.Recipe = *body.Recipe
+		sent["recipe"] = true
+	}
+	if body.Country != nil {
+		post.Country = *body.Country
+		sent["country"] = true
+	}
+	if body.Media != nil {
+		post.Media = *body.Media
+		sent["media"] = true
 	}
 
-	postCollection := config.DB.Collection("posts")
-
-	_, err := postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		http.Error(w, "Could not update post", http.StatusInternalServerError)
+	// only the fields sent are checked, so that posts saved under older rules
+	// can still be changed
+	if errorMessages := onlyFields(validatePost(&post), sent); len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
 		return
 	}
 
-	json.NewEncoder(w).Encode(updateData)
-}
-
-// handles deleting a specific post
-func DeletePost(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	userID, ok := r.Context().Value("userID").(string)
-
-	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+	// media already on the post was checked when it was attached
+	if body.Media != nil && !app.attachMedia(w, r, post.UserID, post.Media) {
 		return
 	}
 
-	postID, _ := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
-
-	objectID, _ := primitive.ObjectIDFromHex(userID)
-	filter := bson.M{"_id": postID, "userID": objectID}
+	if err := app.Posts.Update(r.Context(), post); err != nil {
+		apperror.Write(w, apperror.Internal("Could not update post"))
+		return
+	}
 
-	postCollection := config.DB.Collection("posts")
+	// the update wrote back the media as it was read, perhaps before processing finished
+	if err := app.refreshMediaDetails(r.Context(), &post); err != nil {
+		log.Printf("Could not refresh media of post %s: %v", post.ID.Hex(), err)
+	}
 
-	_, err := postCollection.DeleteOne(context.Background(), filter)
+	post, err := app.Posts.FindByID(r.Context(), post.ID)
 	if err != nil {
-		http.Error(w, "Could not delete post", http.StatusInternalServerError)
+		apperror.Write(w, apperror.Internal("Could not fetch post"))
 		return
 	}
 
-	json.NewEncoder(w).Encode(bson.M{"message": "Post deleted successfully"})
+	json.NewEncoder(w).Encode(post)
 }
 
-// handles adding a comment to a specific post
-func AddComment(w http.ResponseWriter, r *http.Request) {
+// handles deleting a specific post, along with its comments, reactions and place in collections
+func (app *App) DeletePost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	var comment models.Comment
-
-	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		http.Error(w, "Invalid comment data", http.StatusBadRequest)
+	post, ok := app.ownPost(w, r)
+	if !ok {
 		return
 	}
 
-	userID, ok := r.Context().Value("userID").(string)
-	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+	if err := app.Posts.Delete(r.Context(), post.ID, post.UserID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete post"))
 		return
 	}
 
-	enduserID, _ := primitive.ObjectIDFromHex(userID)
-
-	var user models.User
-	userCollection := config.DB.Collection("users")
-	err := userCollection.FindOne(context.Background(), bson.M{"_id": enduserID}).Decode(&user)
-	if err != nil {
-		http.Error(w, "User not found", http.StatusInternalServerError)
+	if err := app.Comments.DeleteByPost(r.Context(), post.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete comments"))
 		return
 	}
-
-	comment.ID = primitive.NewObjectID()
-	comment.UserID = enduserID
-	comment.Name = user.Name
-	comment.Created = time.Now().Unix()
-
-	postID, _ := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
-	filter := bson.M{"_id": postID}
-	update := bson.M{"$push": bson.M{"comments": comment}}
-
-	postCollection := config.DB.Collection("posts")
-	_, err = postCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		http.Error(w, "Could not add comment", http.StatusInternalServerError)
+	if err := app.Reactions.DeleteByPost(r.Context(), post.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not delete reactions"))
+		return
+	}
+	if err := app.Collections.RemovePost(r.Context(), post.ID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not remove post from collections"))
 		return
 	}
 
-	// commentResponse := map[string]interface{}{
-	// 	"id":      comment.ID,
-	// 	"userID":  comment.UserID,
-	// 	"Text":    comment.Text,
-	// 	"Created": comment.Created,
-	// 	"Name":    user.Name,
-	// }
-
-	json.NewEncoder(w).Encode(comment)
+	json.NewEncoder(w).Encode(bson.M{"message": "Post deleted successfully"})
 }
 
-func LikePost(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	userID, ok := r.Context().Value("userID").(string)
+// loads the post named in the URL and checks that the caller wrote it, writing
+// the error response when it doesn't exist or isn't theirs
+func (app *App) ownPost(w http.ResponseWriter, r *http.Request) (models.Post, bool) {
+	userID, ok := currentUserID(r)
 	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
-		return
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
+		return models.Post{}, false
 	}
-	enduserID, _ := primitive.ObjectIDFromHex(userID)
 
-	postID := mux.Vars(r)["id"]
-	objectID, err := primitive.ObjectIDFromHex(postID)
+	postID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
-		return
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return models.Post{}, false
 	}
 
-	postCollection := config.DB.Collection("posts")
-	userCollection := config.DB.Collection("users")
-
-	// Check if the user has already liked the post
-	userFilter := bson.M{"_id": enduserID, "likesList": objectID}
-	var existingUser models.User
-	err = userCollection.FindOne(context.Background(), userFilter).Decode(&existingUser)
-
-	if err == nil {
-		_, _ = userCollection.UpdateOne(context.Background(), bson.M{"_id": enduserID}, bson.M{
-			"$pull": bson.M{"likesList": objectID},
-		})
-		_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-			"$inc": bson.M{"likes": -1},
-		})
-	} else {
-		userDislikeFilter := bson.M{"_id": enduserID, "dislikeList": objectID}
-		err := userCollection.FindOne(context.Background(), userDislikeFilter).Decode(&existingUser)
-		if err == nil {
-			_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-				"$inc": bson.M{"dislikes": -1},
-			})
-		}
-
-		_, _ = userCollection.UpdateOne(context.Background(), bson.M{"_id": enduserID}, bson.M{
-			"$addToSet": bson.M{"likesList": objectID},
-			"$pull":     bson.M{"dislikeList": objectID},
-		})
-
-		_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-			"$inc": bson.M{"likes": 1},
-		})
+	post, err := app.Posts.FindByID(r.Context(), postID)
+	if err == repositories.ErrNotFound {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return models.Post{}, false
 	}
-
-	var post models.Post
-	err = postCollection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
 	if err != nil {
-		http.Error(w, "Failed to retrieve post", http.StatusInternalServerError)
-		return
+		apperror.Write(w, apperror.Internal("Could not fetch post"))
+		return models.Post{}, false
 	}
 
-	response := map[string]interface{}{
-		"likes":    post.Likes,
-		"dislikes": post.Dislikes,
+	if post.UserID != userID {
+		apperror.Write(w, apperror.New(apperror.Forbidden, "You can only change your own posts"))
+		return models.Post{}, false
 	}
+	return post, true
+}
 
-	json.NewEncoder(w).Encode(response)
+// what to tell the user about a video link that can't be embedded
+var videoErrorMessages = map[error]string{
+	models.ErrMalformedVideoURL:   "This link is not a valid URL.",
+	models.ErrUnsupportedVideoURL: "Only YouTube, Vimeo and direct https .mp4 links are supported.",
+	models.ErrMissingVideoID:      "This link does not point to a video.",
 }
 
-func DislikePost(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// trims the post's text and checks it, storing the country under its ISO name
+// and the video link in its canonical form; returns the message for each
+// invalid field, or nil
+func validatePost(post *models.Post) map[string]string {
+	post.Title = strings.TrimSpace(post.Title)
+	post.Description = strings.TrimSpace(post.Description)
+	post.Country = strings.TrimSpace(post.Country)
+	post.VideoURL = strings.TrimSpace(post.VideoURL)
 
-	userID, ok := r.Context().Value("userID").(string)
-	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
-		return
+	errorMessages := make(map[string]string)
+	if err := validate.Struct(post); err != nil {
+		errorMessages = fieldErrors(err)
 	}
-	enduserID, _ := primitive.ObjectIDFromHex(userID)
 
-	postID := mux.Vars(r)["id"]
-	objectID, err := primitive.ObjectIDFromHex(postID)
-	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
-		return
+	if country, ok := models.CanonicalCountry(post.Country); ok {
+		post.Country = country
 	}
 
-	postCollection := config.DB.Collection("posts")
-	userCollection := config.DB.Collection("users")
-
-	// Check if the user has already disliked the post
-	userFilter := bson.M{"_id": enduserID, "dislikeList": objectID}
-	var existingUser models.User
-	err = userCollection.FindOne(context.Background(), userFilter).Decode(&existingUser)
-
-	if err == nil {
-		_, _ = userCollection.UpdateOne(context.Background(), bson.M{"_id": enduserID}, bson.M{
-			"$pull": bson.M{"dislikeList": objectID},
-		})
-		_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-			"$inc": bson.M{"dislikes": -1},
-		})
-	} else {
-		userLikeFilter := bson.M{"_id": enduserID, "likesList": objectID}
-		err := userCollection.FindOne(context.Background(), userLikeFilter).Decode(&existingUser)
-		if err == nil {
-			_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-				"$inc": bson.M{"likes": -1},
-			})
+	if _, invalid := errorMessages["video_url"]; !invalid {
+		if message := normalizeVideo(post); message != "" {
+			errorMessages["video_url"] = message
 		}
+	}
 
-		_, _ = userCollection.UpdateOne(context.Background(), bson.M{"_id": enduserID}, bson.M{
-			"$addToSet": bson.M{"dislikeList": objectID},
-			"$pull":     bson.M{"likesList": objectID},
-		})
+	if len(errorMessages) == 0 {
+		return nil
+	}
+	return errorMessages
+}
 
-		_, _ = postCollection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{
-			"$inc": bson.M{"dislikes": 1},
-		})
+// keeps the messages for the given top-level fields, e.g. "recipe" keeps
+// "recipe.ingredients[0].name"
+func onlyFields(errorMessages map[string]string, fields map[string]bool) map[string]string {
+	kept := make(map[string]string)
+	for path, message := range errorMessages {
+		field := strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' })
+		if len(field) > 0 && fields[field[0]] {
+			kept[path] = message
+		}
 	}
+	return kept
+}
 
-	var post models.Post
-	err = postCollection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
-	if err != nil {
-		http.Error(w, "Failed to retrieve post", http.StatusInternalServerError)
-		return
+// replaces the post's video link with its canonical form and fills in the
+// video it points to, returning why when it can't be embedded
+func normalizeVideo(post *models.Post) string {
+	post.Video = nil
+	if post.VideoURL == "" {
+		return ""
 	}
 
-	response := map[string]interface{}{
-		"likes":    post.Likes,
-		"dislikes": post.Dislikes,
+	video, canonical, err := models.ParseVideoURL(post.VideoURL)
+	if err != nil {
+		return videoErrorMessages[err]
 	}
 
-	json.NewEncoder(w).Encode(response)
+	post.Video = &video
+	post.VideoURL = canonical
+	return ""
 }
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxNameLength    = 50
+	maxBioLength     = 280
+	maxCountryLength = 60
+	maxAvatarLength  = 500
+
+	handleMessage      = "Handles are 3 to 30 lowercase letters, digits or underscores."
+	handleTakenMessage = "Handle is already taken"
+)
+
+// finds a user from the {id} in a URL, which can be their ID or their handle
+func (app *App) findUser(ctx context.Context, idOrHandle string) (models.User, error) {
+	if objectID, err := primitive.ObjectIDFromHex(idOrHandle); err == nil {
+		return app.Users.FindByID(ctx, objectID)
+	}
+	return app.Users.FindByHandle(ctx, models.NormalizeHandle(idOrHandle))
+}
+
+func (app *App) profileOf(ctx context.Context, user models.User) (models.Profile, error) {
+	posts, likes, err := app.Posts.AuthorStats(ctx, user.ID)
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	followers, err := app.Follows.CountFollowers(ctx, models.FollowUser, user.ID.Hex())
+	if err != nil {
+		return models.Profile{}, err
+	}
+	following, err := app.Follows.CountFollowing(ctx, user.ID, models.FollowUser)
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	return models.Profile{
+		ID:            user.ID,
+		Name:          user.Name,
+		Handle:        user.Handle,
+		Bio:           user.Bio,
+		Avatar:        user.Avatar,
+		Country:       user.Country,
+		JoinedAt:      user.CreatedAt,
+		PostCount:     posts,
+		LikesReceived: likes,
+		Followers:     followers,
+		Following:     following,
+	}, nil
+}
+
+// picks a free handle for a new account, made from their name
+func (app *App) newHandle(ctx context.Context, name string) (string, error) {
+	return utils.UniqueHandle(utils.HandleFromName(name), func(handle string) (bool, error) {
+		_, err := app.Users.FindByHandle(ctx, handle)
+		if err == repositories.ErrNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// handles showing a user's public profile
+func (app *App) GetProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, err := app.findUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			apperror.Write(w, apperror.New(apperror.UserNotFound, "User not found"))
+			return
+		}
+		apperror.Write(w, apperror.Internal("Could not fetch user"))
+		return
+	}
+
+	profile, err := app.profileOf(r.Context(), user)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch profile"))
+		return
+	}
+
+	if viewerID, ok := currentUserID(r); ok {
+		profile.FollowedByMe, err = app.Follows.Exists(r.Context(), viewerID, models.FollowUser, user.ID.Hex())
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not fetch profile"))
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(profile)
+}
+
+// handles showing the signed-in user their own profile, with their account details
+func (app *App) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeMyProfile(w, r, user)
+}
+
+// handles editing the signed-in user's profile; fields left out of the body are kept
+func (app *App) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body profileFields
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile := models.ProfileUpdate{
+		Name:    user.Name,
+		Handle:  user.Handle,
+		Bio:     user.Bio,
+		Avatar:  user.Avatar,
+		Country: user.Country,
+	}
+	errorMessages := body.apply(&profile)
+
+	if len(errorMessages) > 0 {
+		apperror.Write(w, apperror.Validation(errorMessages))
+		return
+	}
+
+	if err := app.Users.UpdateProfile(r.Context(), user.ID, profile); err != nil {
+		if err == repositories.ErrHandleTaken {
+			apperror.Write(w, apperror.Validation(map[string]string{"handle": handleTakenMessage}))
+			return
+		}
+		apperror.Write(w, apperror.Internal("Could not update profile"))
+		return
+	}
+
+	// posts keep a copy of the author's name for search
+	if profile.Name != user.Name {
+		if err := app.Posts.SetAuthorName(r.Context(), user.ID, profile.Name); err != nil {
+			apperror.Write(w, apperror.Internal("Could not update posts"))
+			return
+		}
+	}
+
+	user.Name = profile.Name
+	user.Handle = profile.Handle
+	user.Bio = profile.Bio
+	user.Avatar = profile.Avatar
+	user.Country = profile.Country
+	app.writeMyProfile(w, r, user)
+}
+
+// profileFields are the profile fields a request can set; nil fields are left alone
+type profileFields struct {
+	Name    *string `json:"name"`
+	Handle  *string `json:"handle"`
+	Bio     *string `json:"bio"`
+	Avatar  *string `json:"avatar"`
+	Country *string `json:"country"`
+}
+
+// apply copies the fields that were sent onto profile, cleaned up, and returns
+// a message for each one that is not allowed
+func (fields profileFields) apply(profile *models.ProfileUpdate) map[string]string {
+	errorMessages := make(map[string]string)
+
+	if fields.Name != nil {
+		profile.Name = strings.TrimSpace(*fields.Name)
+		if profile.Name == "" {
+			errorMessages["name"] = "Name is required."
+		} else if len(profile.Name) > maxNameLength {
+			errorMessages["name"] = "Name must be at most 50 characters long."
+		}
+	}
+	if fields.Handle != nil {
+		profile.Handle = models.NormalizeHandle(*fields.Handle)
+		if !models.IsHandle(profile.Handle) {
+			errorMessages["handle"] = handleMessage
+		}
+	}
+	if fields.Bio != nil {
+		profile.Bio = strings.TrimSpace(*fields.Bio)
+		if len(profile.Bio) > maxBioLength {
+			errorMessages["bio"] = "Bio must be at most 280 characters long."
+		}
+	}
+	if fields.Avatar != nil {
+		profile.Avatar = strings.TrimSpace(*fields.Avatar)
+		if profile.Avatar != "" && !isWebURL(profile.Avatar) {
+			errorMessages["avatar"] = "Avatar must be an http or https URL."
+		}
+	}
+	if fields.Country != nil {
+		profile.Country = strings.TrimSpace(*fields.Country)
+		if len(profile.Country) > maxCountryLength {
+			errorMessages["country"] = "Country must be at most 60 characters long."
+		}
+	}
+
+	return errorMessages
+}
+
+// handles listing a user's recipes, newest first unless another sort is requested
+func (app *App) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query, err := parseListQuery(r, repositories.SortNewest)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	user, err := app.findUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			apperror.Write(w, apperror.New(apperror.UserNotFound, "User not found"))
+			return
+		}
+		apperror.Write(w, apperror.Internal("Could not fetch user"))
+		return
+	}
+	query.AuthorID = user.ID
+
+	page, err := app.Posts.List(r.Context(), query)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch posts"))
+		return
+	}
+
+	if err := app.personalize(r, page.Posts); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(listResponse(page))
+}
+
+func (app *App) writeMyProfile(w http.ResponseWriter, r *http.Request, user models.User) {
+	profile, err := app.profileOf(r.Context(), user)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch profile"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		models.Profile
+		Email            string `json:"email"`
+		EmailVerified    bool   `json:"emailVerified"`
+		Role             string `json:"role"`
+		TwoFactorEnabled bool   `json:"twoFactorEnabled"`
+	}{
+		Profile:          profile,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified,
+		Role:             user.Role,
+		TwoFactorEnabled: user.TwoFactor.Enabled,
+	})
+}
+
+func isWebURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && len(value) <= maxAvatarLength
+}
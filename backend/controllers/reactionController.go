@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handles toggling one of the configured reaction kinds on a post
+func (app *App) ReactToPost(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Kind string `json:"kind"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	if !isReactionKind(body.Kind) {
+		apperror.Write(w, apperror.Validation(map[string]string{
+			"kind": "Unknown reaction kind, expected one of " + strings.Join(config.ReactionKinds(), ", ") + ".",
+		}))
+		return
+	}
+
+	app.toggleReaction(w, r, body.Kind)
+}
+
+func (app *App) LikePost(w http.ResponseWriter, r *http.Request) {
+	app.toggleReaction(w, r, models.ReactionLike)
+}
+
+func (app *App) DislikePost(w http.ResponseWriter, r *http.Request) {
+	app.toggleReaction(w, r, models.ReactionDislike)
+}
+
+// removes the user's reaction of the given kind if they already had it, otherwise
+// adds it and removes the opposite one, moving the post's counters along
+func (app *App) toggleReaction(w http.ResponseWriter, r *http.Request, kind string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not authenticated"))
+		return
+	}
+	enduserID, _ := primitive.ObjectIDFromHex(userID)
+
+	postID := mux.Vars(r)["id"]
+	objectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	if post, err := app.Posts.FindByID(r.Context(), objectID); err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	// each change to the reactions moves the post's counters by one, and only
+	// when it really happened, so concurrent toggles cannot leave them stale
+	removed, err := app.Reactions.Remove(r.Context(), enduserID, objectID, kind)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not update reaction"))
+		return
+	}
+	if removed {
+		if err := app.Posts.AddReactionCount(r.Context(), objectID, kind, -1); err != nil {
+			apperror.Write(w, apperror.Internal("Could not update reaction counts"))
+			return
+		}
+	} else {
+		reaction := models.Reaction{UserID: enduserID, PostID: objectID, Kind: kind, CreatedAt: time.Now().Unix()}
+		added, err := app.Reactions.Add(r.Context(), reaction)
+		if err != nil {
+			apperror.Write(w, apperror.Internal("Could not update reaction"))
+			return
+		}
+		if added {
+			if err := app.Posts.AddReactionCount(r.Context(), objectID, kind, 1); err != nil {
+				apperror.Write(w, apperror.Internal("Could not update reaction counts"))
+				return
+			}
+		}
+
+		if opposite := models.OppositeReaction(kind); opposite != "" {
+			removed, err := app.Reactions.Remove(r.Context(), enduserID, objectID, opposite)
+			if err != nil {
+				apperror.Write(w, apperror.Internal("Could not update reaction"))
+				return
+			}
+			if removed {
+				if err := app.Posts.AddReactionCount(r.Context(), objectID, opposite, -1); err != nil {
+					apperror.Write(w, apperror.Internal("Could not update reaction counts"))
+					return
+				}
+			}
+		}
+	}
+
+	post, err := app.Posts.FindByID(r.Context(), objectID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch post"))
+		return
+	}
+
+	counts := make(map[string]int)
+	for reactionKind, count := range post.Reactions {
+		if count > 0 {
+			counts[reactionKind] = count
+		}
+	}
+
+	myReactions, err := app.Reactions.KindsByUser(r.Context(), enduserID, objectID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
+	}
+
+	response := map[string]interface{}{
+		"likes":       post.Likes,
+		"dislikes":    post.Dislikes,
+		"reactions":   counts,
+		"myReactions": myReactions,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func isReactionKind(kind string) bool {
+	for _, allowed := range config.ReactionKinds() {
+		if kind == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// fills in the reactions the signed-in user left on each post; anonymous
+// requests are left as they are
+func (app *App) personalize(r *http.Request, posts []models.PostSummary) error {
+	userID, ok := currentUserID(r)
+	if !ok || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]primitive.ObjectID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	kinds, err := app.Reactions.KindsByUserForPosts(r.Context(), userID, postIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].MyReactions = kinds[posts[i].ID]
+		if posts[i].MyReactions == nil {
+			posts[i].MyReactions = []string{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handles viewing a post's recipe at a different serving count and in metric or imperial units
+func (app *App) GetRecipe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	objectID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid post ID"))
+		return
+	}
+
+	units := r.URL.Query().Get("units")
+	if units != "" && units != utils.Metric && units != utils.Imperial {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, "Units must be metric or imperial"))
+		return
+	}
+
+	post, err := app.Posts.FindByID(r.Context(), objectID)
+	if err != nil || post.Hidden {
+		apperror.Write(w, apperror.New(apperror.PostNotFound, "Post not found"))
+		return
+	}
+
+	servings := post.Recipe.Servings
+	if value := r.URL.Query().Get("servings"); value != "" {
+		servings, err = strconv.Atoi(value)
+		if err != nil || servings < 1 || servings > 100 {
+			apperror.Write(w, apperror.New(apperror.InvalidQuery, "Servings must be a number between 1 and 100"))
+			return
+		}
+		if post.Recipe.Servings == 0 {
+			apperror.Write(w, apperror.New(apperror.RecipeNotScalable, "This recipe does not say how many servings it makes"))
+			return
+		}
+	}
+
+	response := map[string]interface{}{
+		"servings":    servings,
+		"units":       units,
+		"prepMinutes": post.Recipe.PrepMinutes,
+		"cookMinutes": post.Recipe.CookMinutes,
+		"ingredients": utils.ScaleRecipe(post.Recipe, servings, units),
+		"steps":       post.Recipe.Steps,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
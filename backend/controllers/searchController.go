@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/gorilla/mux"
+)
+
+type searchResult struct {
+	models.PostSummary
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// handles full-text search over posts, ranked by relevance
+func (app *App) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := strings.TrimSpace(mux.Vars(r)["query"])
+	if query == "" {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, "Search query is required"))
+		return
+	}
+
+	// only the limit and after parameters apply; results are always ranked by relevance
+	listQuery, err := parseListQuery(r, repositories.SortNewest)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidQuery, err.Error()))
+		return
+	}
+
+	page, err := app.Posts.Search(r.Context(), query, listQuery.Limit, listQuery.After)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not search posts"))
+		return
+	}
+	hits := page.Hits
+
+	summaries := make([]models.PostSummary, len(hits))
+	for i, hit := range hits {
+		summaries[i] = hit.Post
+	}
+	if err := app.personalize(r, summaries); err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch reactions"))
+		return
+	}
+
+	terms := utils.SearchTerms(query)
+	results := make([]searchResult, 0, len(hits))
+
+	for i, hit := range hits {
+		results = append(results, searchResult{
+			PostSummary: summaries[i],
+			Score:       hit.Score,
+			Highlights: map[string]string{
+				"title":       utils.Highlight(hit.Post.Title, terms),
+				"description": utils.Snippet(hit.Post.Description, terms),
+				"ingredients": utils.Snippet(strings.Join(hit.Post.Ingredients, ", "), terms),
+				"country":     utils.Highlight(hit.Post.Country, terms),
+				"userName":    utils.Highlight(hit.Post.UserName, terms),
+			},
+		})
+	}
+
+	var nextCursor interface{}
+	if page.Next != nil {
+		nextCursor = encodeCursor(page.Next)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"posts":      results,
+		"total":      page.Total,
+		"nextCursor": nextCursor,
+	})
+}
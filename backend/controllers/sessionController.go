@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+// the refresh cookie is only sent to the auth endpoints
+const refreshCookiePath = "/auth"
+
+// creates a session for the user and sets the access and refresh token cookies
+func (app *App) startSession(w http.ResponseWriter, r *http.Request, user models.User) (map[string]string, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     user.ID,
+		UserAgent:  r.UserAgent(),
+		IP:         clientIP(r),
+		CreatedAt:  now.Unix(),
+		LastUsedAt: now.Unix(),
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL).Unix(),
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken(session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshHash = utils.HashToken(refreshToken)
+
+	if err := app.Sessions.Create(r.Context(), session); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(w, user, session.ID, refreshToken)
+}
+
+func issueTokens(w http.ResponseWriter, user models.User, sessionID primitive.ObjectID, refreshToken string) (map[string]string, error) {
+	token, err := utils.GenerateJWT(user.ID.Hex(), user.Name, user.Role, sessionID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "token",
+		Value:    token,
+		HttpOnly: true,
+		Expires:  time.Now().Add(utils.AccessTokenTTL),
+		Path:     "/",
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		HttpOnly: true,
+		Expires:  time.Now().Add(utils.RefreshTokenTTL),
+		Path:     refreshCookiePath,
+	})
+
+	return map[string]string{"token": token, "refreshToken": refreshToken}, nil
+}
+
+func clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "token",
+		Value:    "",
+		HttpOnly: true,
+		Expires:  time.Now().Add(-1 * time.Hour),
+		Path:     "/",
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		HttpOnly: true,
+		Expires:  time.Now().Add(-1 * time.Hour),
+		Path:     refreshCookiePath,
+	})
+}
+
+// reads the refresh token from its cookie, or from the body for clients without cookies
+func refreshTokenFromRequest(r *http.Request) string {
+	if cookie, err := r.Cookie("refresh_token"); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	var body struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	return body.RefreshToken
+}
+
+// looks up the session a refresh token belongs to, without checking the token is still current
+func (app *App) sessionFromRefreshToken(r *http.Request, refreshToken string) (models.Session, error) {
+	sessionID, ok := utils.RefreshTokenSession(refreshToken)
+	if !ok {
+		return models.Session{}, errInvalidRefreshToken
+	}
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return models.Session{}, errInvalidRefreshToken
+	}
+
+	session, err := app.Sessions.FindByID(r.Context(), objectID)
+	if err != nil {
+		return models.Session{}, errInvalidRefreshToken
+	}
+	if session.RefreshHash != utils.HashToken(refreshToken) {
+		return session, errInvalidRefreshToken
+	}
+	return session, nil
+}
+
+// RefreshToken swaps a refresh token for a new access token and a new refresh token
+func (app *App) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	refreshToken := refreshTokenFromRequest(r)
+
+	session, err := app.sessionFromRefreshToken(r, refreshToken)
+	if err != nil {
+		// a refresh token that has already been rotated away means it was
+		// copied, so the whole session is ended
+		if session.PreviousHash != "" && session.PreviousHash == utils.HashToken(refreshToken) {
+			app.Sessions.Revoke(r.Context(), session.ID)
+		}
+		clearAuthCookies(w)
+		apperror.Write(w, apperror.New(apperror.SessionExpired, "Invalid refresh token"))
+		return
+	}
+
+	if !session.Active(time.Now().Unix()) {
+		clearAuthCookies(w)
+		apperror.Write(w, apperror.New(apperror.SessionExpired, "Session has expired"))
+		return
+	}
+
+	user, err := app.Users.FindByID(r.Context(), session.UserID)
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, "User not found"))
+		return
+	}
+
+	newRefreshToken, err := utils.GenerateRefreshToken(session.ID.Hex())
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error generating token"))
+		return
+	}
+
+	expiresAt := time.Now().Add(utils.RefreshTokenTTL).Unix()
+	rotated, err := app.Sessions.Rotate(r.Context(), session.ID, session.RefreshHash, utils.HashToken(newRefreshToken), expiresAt)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not refresh session"))
+		return
+	}
+	if !rotated {
+		apperror.Write(w, apperror.New(apperror.SessionExpired, "Invalid refresh token"))
+		return
+	}
+
+	tokens, err := issueTokens(w, user, session.ID, newRefreshToken)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error generating token"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// GetSessions lists the signed-in user's active sessions
+func (app *App) GetSessions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := primitive.ObjectIDFromHex(r.Context().Value("userID").(string))
+	currentID, _ := r.Context().Value("sessionID").(string)
+
+	sessions, err := app.Sessions.ListActiveByUser(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not fetch sessions"))
+		return
+	}
+
+	type sessionResponse struct {
+		models.Session
+		Current bool `json:"current"`
+	}
+
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{Session: session, Current: session.ID.Hex() == currentID})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"sessions": response})
+}
+
+// RevokeSession signs one of the user's devices out
+func (app *App) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, _ := primitive.ObjectIDFromHex(r.Context().Value("userID").(string))
+
+	sessionID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidID, "Invalid session ID"))
+		return
+	}
+
+	session, err := app.Sessions.FindByID(r.Context(), sessionID)
+	if err != nil || session.UserID != userID {
+		apperror.Write(w, apperror.New(apperror.SessionNotFound, "Session not found"))
+		return
+	}
+
+	if err := app.Sessions.Revoke(r.Context(), sessionID); err != nil {
+		apperror.Write(w, apperror.Internal("Could not revoke session"))
+		return
+	}
+
+	if current, _ := r.Context().Value("sessionID").(string); current == sessionID.Hex() {
+		clearAuthCookies(w)
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Session revoked successfully"})
+}
+
+// the address the request came from, unless that is a trusted proxy: then the
+// client is the last address in X-Forwarded-For that no trusted proxy added.
+// Anything further left was written by the client and can't be believed
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !config.IsTrustedProxy(net.ParseIP(host)) {
+		return host
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			break
+		}
+		host = hop
+		if !config.IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return host
+}
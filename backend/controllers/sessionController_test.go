@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 172.16.0.0/12")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"forwarded header from an untrusted peer", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"through a trusted proxy", "10.0.0.1:5000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed entries left of the proxy's", "10.0.0.1:5000", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"through two trusted proxies", "10.0.0.1:5000", "198.51.100.1, 172.20.0.5", "198.51.100.1"},
+		{"trusted proxy without the header", "10.0.0.1:5000", "", "10.0.0.1"},
+		{"garbage in the header", "10.0.0.1:5000", "not an ip", "10.0.0.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remoteAddr
+			if test.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", test.forwarded)
+			}
+			if got := clientIP(r); got != test.want {
+				t.Errorf("clientIP = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const recoveryCodeCount = 10
+
+// SetupTwoFactor starts enrolling the user in 2FA, returning the secret as an
+// otpauth URI and QR code; it takes effect once EnableTwoFactor confirms a code
+func (app *App) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactor.Enabled {
+		apperror.Write(w, apperror.New(apperror.TwoFactorAlreadyEnabled, "Two-factor authentication is already enabled"))
+		return
+	}
+
+	enrollment, err := utils.GenerateTOTP(user.Email)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not generate secret"))
+		return
+	}
+
+	if err := app.Users.SetTwoFactor(r.Context(), user.ID, models.TwoFactor{Secret: enrollment.Secret}); err != nil {
+		apperror.Write(w, apperror.Internal("Could not start two-factor setup"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// EnableTwoFactor turns 2FA on once the user proves their app produces codes,
+// and returns the recovery codes, which are never shown again
+func (app *App) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactor.Enabled {
+		apperror.Write(w, apperror.New(apperror.TwoFactorAlreadyEnabled, "Two-factor authentication is already enabled"))
+		return
+	}
+	if user.TwoFactor.Secret == "" {
+		apperror.Write(w, apperror.New(apperror.TwoFactorSetupRequired, "Start two-factor setup first"))
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TwoFactor.Secret, body.Code, time.Now())
+	if !valid {
+		apperror.Write(w, apperror.Validation(map[string]string{"code": "Invalid code"}))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not generate recovery codes"))
+		return
+	}
+
+	twoFactor := models.TwoFactor{Secret: user.TwoFactor.Secret, Enabled: true, RecoveryCodes: hashes, LastStep: step}
+	if err := app.Users.SetTwoFactor(r.Context(), user.ID, twoFactor); err != nil {
+		apperror.Write(w, apperror.Internal("Could not enable two-factor authentication"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"recoveryCodes": codes})
+}
+
+// VerifyTwoFactor finishes a login that needed a second factor, taking either a
+// code from the user's app or one of their recovery codes
+func (app *App) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	userID, ok := utils.ParseChallengeToken(body.ChallengeToken)
+	if !ok {
+		apperror.Write(w, apperror.New(apperror.SessionExpired, "Login has expired, please sign in again"))
+		return
+	}
+	objectID, _ := primitive.ObjectIDFromHex(userID)
+
+	user, err := app.Users.FindByID(r.Context(), objectID)
+	if err != nil || !user.TwoFactor.Enabled {
+		apperror.Write(w, apperror.New(apperror.SessionExpired, "Login has expired, please sign in again"))
+		return
+	}
+
+	// codes are throttled and counted towards a lockout just like passwords
+	now := time.Now()
+	keys := []string{"ip:" + clientIP(r), "2fa:" + userID}
+
+	if wait := loginWait(keys, now); wait > 0 {
+		app.recordLogin(r, user.ID, false, models.LoginThrottled)
+		writeTooManyAttempts(w, wait)
+		return
+	}
+	if user.LockedUntil > now.Unix() {
+		failLogin(keys, now)
+		app.recordLogin(r, user.ID, false, models.LoginLocked)
+		writeTooManyAttempts(w, time.Unix(user.LockedUntil, 0).Sub(now))
+		return
+	}
+
+	valid, err := app.checkSecondFactor(r, user, body.Code, body.RecoveryCode)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not check code"))
+		return
+	}
+	if !valid {
+		failLogin(keys, now)
+		app.countFailedLogin(r.Context(), user.ID, now)
+		app.recordLogin(r, user.ID, false, models.LoginInvalidCode)
+		apperror.Write(w, apperror.New(apperror.InvalidCredentials, "Invalid code").WithDetails(map[string]string{"code": "Invalid code"}))
+		return
+	}
+
+	loginBackoff.Reset(keys[1])
+	app.loginSucceeded(r, user)
+
+	tokens, err := app.startSession(w, r, user)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Error generating token"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// DisableTwoFactor turns 2FA off after the user confirms their password
+func (app *App) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !user.TwoFactor.Enabled {
+		apperror.Write(w, apperror.New(apperror.TwoFactorNotEnabled, "Two-factor authentication is not enabled"))
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)) != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidCredentials, "Invalid password").WithDetails(map[string]string{"password": "Invalid password"}))
+		return
+	}
+
+	if err := app.Users.SetTwoFactor(r.Context(), user.ID, models.TwoFactor{}); err != nil {
+		apperror.Write(w, apperror.Internal("Could not disable two-factor authentication"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a code from their app
+func (app *App) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperror.Write(w, apperror.New(apperror.InvalidInput, "Invalid input"))
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !user.TwoFactor.Enabled {
+		apperror.Write(w, apperror.New(apperror.TwoFactorNotEnabled, "Two-factor authentication is not enabled"))
+		return
+	}
+
+	valid, err := app.checkSecondFactor(r, user, body.Code, "")
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not check code"))
+		return
+	}
+	if !valid {
+		apperror.Write(w, apperror.Validation(map[string]string{"code": "Invalid code"}))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		apperror.Write(w, apperror.Internal("Could not generate recovery codes"))
+		return
+	}
+
+	// the user was read before the code was used, so its last step is refreshed here
+	if latest, err := app.Users.FindByID(r.Context(), user.ID); err == nil {
+		user = latest
+	}
+	user.TwoFactor.RecoveryCodes = hashes
+	if err := app.Users.SetTwoFactor(r.Context(), user.ID, user.TwoFactor); err != nil {
+		apperror.Write(w, apperror.Internal("Could not save recovery codes"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"recoveryCodes": codes})
+}
+
+// checks a TOTP code, or failing that a recovery code, using it up either way
+func (app *App) checkSecondFactor(r *http.Request, user models.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, valid := utils.ValidateTOTP(user.TwoFactor.Secret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+		return app.Users.UseTOTPStep(r.Context(), user.ID, step)
+	}
+
+	if recoveryCode != "" {
+		return app.Users.UseRecoveryCode(r.Context(), user.ID, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	return false, nil
+}
+
+// loads the signed-in user, writing the error response when they cannot be found
+func (app *App) currentUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	userID, _ := currentUserID(r)
+
+	user, err := app.Users.FindByID(r.Context(), userID)
+	if err != nil {
+		apperror.Write(w, apperror.Internal("User not found"))
+		return models.User{}, false
+	}
+	return user, true
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+	return codes, hashes, nil
+}
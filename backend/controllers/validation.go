@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// errors name fields as they appear in the JSON, e.g. "video_url" rather than "VideoURL"
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	validate.RegisterValidation("unit", func(fl validator.FieldLevel) bool {
+		return models.IsKnownUnit(fl.Field().String())
+	})
+	validate.RegisterValidation("country", func(fl validator.FieldLevel) bool {
+		return models.IsCountry(fl.Field().String())
+	})
+}
+
+// turns validator errors into a map keyed by the JSON path of each field,
+// e.g. "recipe.ingredients[0].name"
+func fieldErrors(err error) map[string]string {
+	errorMessages := make(map[string]string)
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		errorMessages["error"] = "Invalid input."
+		return errorMessages
+	}
+
+	for _, fieldErr := range validationErrors {
+		errorMessages[jsonPath(fieldErr.Namespace())] = fieldMessage(fieldErr)
+	}
+
+	return errorMessages
+}
+
+// drops the struct name and lower-cases the first letter of each segment
+func jsonPath(namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	for i, segment := range segments {
+		segments[i] = strings.ToLower(segment[:1]) + segment[1:]
+	}
+	return strings.Join(segments, ".")
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "This field is required."
+	case "min":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("Must contain at least %s item(s).", fieldErr.Param())
+		}
+		return fmt.Sprintf("Must be at least %s.", fieldErr.Param())
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("Must contain at most %s items.", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("Must be at most %s characters long.", fieldErr.Param())
+		}
+		return fmt.Sprintf("Must be at most %s.", fieldErr.Param())
+	case "unit":
+		return "Unknown unit."
+	case "country":
+		return "Unknown country."
+	case "url", "http_url":
+		return "Must be a valid URL."
+	default:
+		return "Invalid input."
+	}
+}
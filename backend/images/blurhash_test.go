@@ -0,0 +1,71 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func filled(width, height int, pixel func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+func decode83(digits string) int {
+	value := 0
+	for _, digit := range digits {
+		value = value*83 + strings.IndexRune(base83, digit)
+	}
+	return value
+}
+
+func TestBlurhashLayout(t *testing.T) {
+	red := filled(32, 24, func(x, y int) color.NRGBA { return color.NRGBA{R: 255, A: 255} })
+
+	tests := []struct {
+		xComponents, yComponents int
+		size                     string
+	}{
+		{4, 3, "L"},
+		{3, 4, "T"},
+		{1, 1, "0"},
+	}
+	for _, test := range tests {
+		hash := Blurhash(red, test.xComponents, test.yComponents)
+		if want := 4 + 2*test.xComponents*test.yComponents; len(hash) != want {
+			t.Errorf("%dx%d: %q is %d characters, want %d", test.xComponents, test.yComponents, hash, len(hash), want)
+		}
+		if hash[:1] != test.size {
+			t.Errorf("%dx%d: size flag of %q is %q, want %q", test.xComponents, test.yComponents, hash, hash[:1], test.size)
+		}
+		// the average color, exactly red
+		if hash[2:6] != "TI:j" {
+			t.Errorf("%dx%d: average color of %q is %q, want TI:j", test.xComponents, test.yComponents, hash, hash[2:6])
+		}
+	}
+}
+
+func TestBlurhashDirection(t *testing.T) {
+	// dark on the left, light on the right, and its mirror image
+	gradient := filled(32, 32, func(x, y int) color.NRGBA {
+		v := uint8(x * 255 / 31)
+		return color.NRGBA{R: v, G: v, B: v, A: 255}
+	})
+	mirrored := orient(gradient, 2)
+
+	// the red part of the first horizontal component; 9 means none
+	firstRed := func(hash string) int { return decode83(hash[6:8]) / (19 * 19) }
+
+	if got := firstRed(Blurhash(gradient, 4, 3)); got >= 9 {
+		t.Errorf("dark-to-light gradient: first component is %d, want below 9", got)
+	}
+	if got := firstRed(Blurhash(mirrored, 4, 3)); got <= 9 {
+		t.Errorf("light-to-dark gradient: first component is %d, want above 9", got)
+	}
+}
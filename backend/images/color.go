@@ -0,0 +1,51 @@
+package images
+
+import (
+	"fmt"
+	"image"
+)
+
+// DominantColor returns the most common color of the image as "#rrggbb",
+// ignoring transparent pixels, or "" when every pixel is transparent. Colors
+// are counted in coarse buckets, so that many slightly different shades of
+// the same color win over a few identical pixels
+func DominantColor(img image.Image) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var best *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			// back to straight 8-bit channels
+			r, g, b = r*0xFF/a, g*0xFF/a, b*0xFF/a
+
+			key := int(r>>4)<<8 | int(g>>4)<<4 | int(b>>4)
+			current := buckets[key]
+			if current == nil {
+				current = &bucket{}
+				buckets[key] = current
+			}
+			current.count++
+			current.r += int(r)
+			current.g += int(g)
+			current.b += int(b)
+
+			if best == nil || current.count > best.count {
+				best = current
+			}
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,151 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// a JPEG segment with the given marker and payload
+func jpegSegment(marker byte, payload string) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// EXIF holding an orientation and a pointer to GPS coordinates, little-endian like most cameras write it
+func cameraEXIF(orientation byte) string {
+	return "Exif\x00\x00II\x2A\x00\x08\x00\x00\x00" +
+		"\x02\x00" +
+		"\x12\x01\x03\x00\x01\x00\x00\x00" + string([]byte{orientation}) + "\x00\x00\x00" +
+		"\x25\x88\x04\x00\x01\x00\x00\x00\x26\x00\x00\x00" + // the GPS IFD
+		"\x00\x00\x00\x00" +
+		"GPS 43.6532N 79.3832W"
+}
+
+func TestStripJPEG(t *testing.T) {
+	var encoded bytes.Buffer
+	img := filled(8, 8, func(x, y int) color.NRGBA { return color.NRGBA{R: 200, G: 100, B: 50, A: 255} })
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, orientation := range []byte{1, 6} {
+		var data []byte
+		data = append(data, encoded.Bytes()[:2]...)
+		data = append(data, jpegSegment(markerAPP1, cameraEXIF(orientation))...)
+		data = append(data, jpegSegment(markerAPP13, "Photoshop 3.0\x00 Toronto")...)
+		data = append(data, jpegSegment(markerCOM, "taken at home")...)
+		data = append(data, encoded.Bytes()[2:]...)
+
+		stripped, err := StripMetadata(data, "image/jpeg")
+		if err != nil {
+			t.Fatalf("orientation %d: %v", orientation, err)
+		}
+		for _, secret := range []string{"GPS", "Toronto", "taken at home"} {
+			if bytes.Contains(stripped, []byte(secret)) {
+				t.Errorf("orientation %d: %q was kept", orientation, secret)
+			}
+		}
+		if got := jpegOrientation(stripped); got != int(orientation) {
+			t.Errorf("orientation %d: stripped image has orientation %d", orientation, got)
+		}
+		if orientation == 1 && bytes.Contains(stripped, []byte("Exif")) {
+			t.Errorf("orientation 1: an EXIF segment was written back")
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+			t.Errorf("orientation %d: stripped image does not decode: %v", orientation, err)
+		}
+	}
+}
+
+// a PNG chunk with its length and CRC
+func pngChunk(kind, data string) []byte {
+	chunk := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, kind+data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(kind+data)))
+}
+
+func TestStripPNG(t *testing.T) {
+	var encoded bytes.Buffer
+	img := filled(8, 8, func(x, y int) color.NRGBA { return color.NRGBA{G: 255, A: 255} })
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatal(err)
+	}
+
+	// the signature and IHDR come first
+	header := 8 + 12 + 13
+	var data []byte
+	data = append(data, encoded.Bytes()[:header]...)
+	data = append(data, pngChunk("tEXt", "Location\x00Toronto")...)
+	data = append(data, pngChunk("eXIf", "MM\x00\x2A")...)
+	data = append(data, pngChunk("tIME", "\x07\xE8\x05\x18\x0C\x00\x00")...)
+	data = append(data, encoded.Bytes()[header:]...)
+
+	stripped, err := StripMetadata(data, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stripped, encoded.Bytes()) {
+		t.Errorf("stripped PNG is not the original image without its metadata")
+	}
+}
+
+// a WebP chunk, padded to an even size
+func webpChunk(kind, data string) []byte {
+	chunk := append([]byte(kind), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := "\x0C\x00\x00\x00" + "\x07\x00\x00" + "\x07\x00\x00" // EXIF and XMP flags, 8x8
+	image := "image data"
+
+	riff := func(chunks ...[]byte) []byte {
+		data := []byte("RIFF\x00\x00\x00\x00WEBP")
+		for _, chunk := range chunks {
+			data = append(data, chunk...)
+		}
+		binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+		return data
+	}
+
+	data := riff(webpChunk("VP8X", vp8x), webpChunk("VP8L", image), webpChunk("EXIF", "GPS 43N"), webpChunk("XMP ", "<x:xmpmeta/>"))
+	want := riff(webpChunk("VP8X", "\x00"+vp8x[1:]), webpChunk("VP8L", image))
+
+	stripped, err := StripMetadata(data, "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stripped, want) {
+		t.Errorf("got  %q\nwant %q", stripped, want)
+	}
+}
+
+func TestStripMetadataMalformed(t *testing.T) {
+	for _, contentType := range []string{"image/jpeg", "image/png", "image/webp"} {
+		if _, err := StripMetadata([]byte("not an image"), contentType); err == nil {
+			t.Errorf("%s: malformed data was accepted", contentType)
+		}
+	}
+
+	truncated := append([]byte{0xFF, 0xD8}, jpegSegment(markerCOM, "comment")[:6]...)
+	if _, err := StripMetadata(truncated, "image/jpeg"); err == nil {
+		t.Errorf("a truncated JPEG segment was accepted")
+	}
+
+	gif := []byte("GIF89a")
+	if stripped, err := StripMetadata(gif, "image/gif"); err != nil || !bytes.Equal(stripped, gif) {
+		t.Errorf("other types should be left alone: got %q, %v", stripped, err)
+	}
+}
@@ -0,0 +1,147 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/storage"
+)
+
+const (
+	queueSize = 100
+	// uploads are limited to 10 MB already; this guards against whatever else is in the store
+	maxSourceSize = 20 << 20
+	jobTimeout    = 2 * time.Minute
+	// how long marking a failed image may take, once the job itself has given up
+	markTimeout = 10 * time.Second
+)
+
+// Pipeline makes the variants of uploaded images in the background, then
+// records them on the upload and on every post it is attached to
+type Pipeline struct {
+	blobs storage.BlobStore
+	media repositories.MediaRepository
+	posts repositories.PostRepository
+	jobs  chan models.Media
+}
+
+// NewPipeline starts a Pipeline processing up to workers images at a time
+func NewPipeline(blobs storage.BlobStore, media repositories.MediaRepository, posts repositories.PostRepository, workers int) *Pipeline {
+	pipeline := &Pipeline{
+		blobs: blobs,
+		media: media,
+		posts: posts,
+		jobs:  make(chan models.Media, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go pipeline.work()
+	}
+	return pipeline
+}
+
+// Enqueue schedules an uploaded image for processing. When the queue is full
+// the image stays pending until Resume picks it up on the next start
+func (pipeline *Pipeline) Enqueue(media models.Media) {
+	select {
+	case pipeline.jobs <- media:
+	default:
+		log.Printf("Image queue is full, %s stays pending", media.ID.Hex())
+	}
+}
+
+// Resume queues the images that were still pending when the server last stopped
+func (pipeline *Pipeline) Resume(ctx context.Context) error {
+	pending, err := pipeline.media.ListPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for _, media := range pending {
+			pipeline.jobs <- media
+		}
+	}()
+	return nil
+}
+
+func (pipeline *Pipeline) work() {
+	for media := range pipeline.jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+		if err := pipeline.process(ctx, media); err != nil {
+			log.Printf("Could not process image %s: %v", media.ID.Hex(), err)
+			pipeline.markFailed(media)
+		}
+		cancel()
+	}
+}
+
+// the job's context may be the reason it failed, so a fresh one is used
+func (pipeline *Pipeline) markFailed(media models.Media) {
+	ctx, cancel := context.WithTimeout(context.Background(), markTimeout)
+	defer cancel()
+
+	if err := pipeline.media.SetImageDetails(ctx, media.ID, models.MediaFailed, models.ImageDetails{}); err != nil {
+		log.Printf("Could not mark image %s as failed: %v", media.ID.Hex(), err)
+	}
+}
+
+func (pipeline *Pipeline) process(ctx context.Context, media models.Media) error {
+	blob, err := pipeline.blobs.Get(ctx, media.Key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(blob, maxSourceSize))
+	blob.Close()
+	if err != nil {
+		return err
+	}
+
+	result, err := Process(data)
+	if err != nil {
+		return err
+	}
+
+	details := models.ImageDetails{
+		Width:    result.Width,
+		Height:   result.Height,
+		Blurhash: result.Blurhash,
+		Color:    result.Color,
+	}
+
+	// "<user>/<id>.jpg" has its variants under "<user>/<id>/"
+	dir := strings.TrimSuffix(media.Key, path.Ext(media.Key))
+	for _, variant := range result.Variants {
+		webpKey := dir + "/" + variant.Name + ".webp"
+		jpegKey := dir + "/" + variant.Name + ".jpg"
+
+		if err := pipeline.blobs.Put(ctx, webpKey, bytes.NewReader(variant.WebP), int64(len(variant.WebP)), "image/webp"); err != nil {
+			return err
+		}
+		if err := pipeline.blobs.Put(ctx, jpegKey, bytes.NewReader(variant.JPEG), int64(len(variant.JPEG)), "image/jpeg"); err != nil {
+			return err
+		}
+
+		details.Variants = append(details.Variants, models.MediaVariant{
+			Name:   variant.Name,
+			Width:  variant.Width,
+			Height: variant.Height,
+			WebP:   pipeline.blobs.URL(webpKey),
+			JPEG:   pipeline.blobs.URL(jpegKey),
+		})
+	}
+
+	if err := pipeline.media.SetImageDetails(ctx, media.ID, models.MediaReady, details); err != nil {
+		return err
+	}
+
+	// posts copy the details of their media when it is attached, which may have
+	// been before they were ready
+	return pipeline.posts.SetMediaDetails(ctx, media.ID, details)
+}
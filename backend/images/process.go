@@ -0,0 +1,233 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// decoders for the image types that can be uploaded
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/gen2brain/webp"
+	"golang.org/x/image/draw"
+)
+
+// images are decoded in full, so larger ones are refused rather than risk running out of memory
+const maxPixels = 50_000_000
+
+const (
+	webpQuality = 80
+	jpegQuality = 82
+)
+
+// Variant is a size images are resized to. Images are cropped to fill both
+// dimensions, unless Height is 0 and the image only scaled to the width
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Variants are the sizes made of every uploaded image: a square thumbnail, the
+// picture on a recipe card and the wide one at the top of a recipe
+var Variants = []Variant{
+	{Name: models.VariantThumbnail, Width: 160, Height: 160},
+	{Name: models.VariantCard, Width: 640, Height: 480},
+	{Name: models.VariantHero, Width: 1600},
+}
+
+// Encoded is a variant of an image in both of the formats it is served in
+type Encoded struct {
+	Variant
+	WebP []byte
+	JPEG []byte
+}
+
+// Result is what processing an image makes of it
+type Result struct {
+	Width    int
+	Height   int
+	Blurhash string
+	Color    string
+	Variants []Encoded
+}
+
+// Process decodes a JPEG, PNG, GIF or WebP image, turns it upright and makes
+// its variants, placeholder and dominant color. Re-encoding leaves all
+// metadata behind. Images are never scaled up, so the variants of small
+// images are smaller than asked for
+func Process(data []byte) (Result, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return Result{}, fmt.Errorf("image is %dx%d, more than %d pixels", config.Width, config.Height, maxPixels)
+	}
+
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var img *image.NRGBA
+	if format == "jpeg" {
+		img = orient(decoded, jpegOrientation(data))
+	} else {
+		img = orient(decoded, 1)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return Result{}, errors.New("image is empty")
+	}
+
+	// the placeholder needs no more detail than this
+	small := resize(img, Variant{Width: 64})
+
+	result := Result{
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Blurhash: Blurhash(small, 4, 3),
+		Color:    DominantColor(small),
+	}
+	if bounds.Dy() > bounds.Dx() {
+		result.Blurhash = Blurhash(small, 3, 4)
+	}
+
+	for _, variant := range Variants {
+		resized := resize(img, variant)
+		encoded := Encoded{Variant: variant}
+		encoded.Width, encoded.Height = resized.Bounds().Dx(), resized.Bounds().Dy()
+
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, resized, webp.Options{Quality: webpQuality}); err != nil {
+			return Result{}, err
+		}
+		encoded.WebP = buf.Bytes()
+
+		buf = bytes.Buffer{}
+		if err := jpeg.Encode(&buf, onWhite(resized), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Result{}, err
+		}
+		encoded.JPEG = buf.Bytes()
+
+		result.Variants = append(result.Variants, encoded)
+	}
+
+	return result, nil
+}
+
+// reads the EXIF orientation of a JPEG, 1 when it has none
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == markerSOS {
+			break
+		}
+		end := i + 2 + int(data[i+2])<<8 + int(data[i+3])
+		if end > len(data) {
+			break
+		}
+		if marker == markerAPP1 {
+			if orientation := exifOrientation(data[i+4 : end]); orientation > 0 {
+				return orientation
+			}
+		}
+		i = end
+	}
+	return 1
+}
+
+// copies the image, rotating and flipping it as its EXIF orientation says it should be shown
+func orient(src image.Image, orientation int) *image.NRGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// orientations 5 to 8 turn the image a quarter
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 && orientation <= 8 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	if orientation <= 1 || orientation > 8 {
+		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = width-1-x, y
+			case 3: // upside down
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored upside down
+				dx, dy = x, height-1-y
+			case 5: // mirrored along the diagonal
+				dx, dy = y, x
+			case 6: // turned a quarter to the left, so turned right to show
+				dx, dy = height-1-y, x
+			case 7: // mirrored along the other diagonal
+				dx, dy = height-1-y, width-1-x
+			case 8: // turned a quarter to the right, so turned left to show
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, src.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// scales the image down to the variant, cropping the middle of it to fill both dimensions
+func resize(img *image.NRGBA, variant Variant) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	crop := bounds
+
+	dstWidth, dstHeight := variant.Width, variant.Height
+	if dstHeight == 0 {
+		if dstWidth > width {
+			dstWidth = width
+		}
+		dstHeight = max(1, height*dstWidth/width)
+	} else {
+		// the part of the image with the variant's aspect ratio
+		if width*dstHeight > height*dstWidth {
+			cropWidth := height * dstWidth / dstHeight
+			crop.Min.X += (width - cropWidth) / 2
+			crop.Max.X = crop.Min.X + cropWidth
+		} else {
+			cropHeight := width * dstHeight / dstWidth
+			crop.Min.Y += (height - cropHeight) / 2
+			crop.Max.Y = crop.Min.Y + cropHeight
+		}
+		if crop.Dx() < dstWidth {
+			dstWidth, dstHeight = max(1, crop.Dx()), max(1, crop.Dy())
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
+
+// JPEG has no transparency, so transparent parts are shown on white rather than black
+func onWhite(img *image.NRGBA) image.Image {
+	if img.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,92 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// an image whose pixels are numbered 1, 2, 3 along the top row and 4, 5, 6 below
+func numbered() *image.NRGBA {
+	return filled(3, 2, func(x, y int) color.NRGBA { return color.NRGBA{R: uint8(y*3 + x + 1), A: 255} })
+}
+
+func pixels(img *image.NRGBA) [][]uint8 {
+	bounds := img.Bounds()
+	rows := make([][]uint8, bounds.Dy())
+	for y := range rows {
+		for x := 0; x < bounds.Dx(); x++ {
+			rows[y] = append(rows[y], img.NRGBAAt(x, y).R)
+		}
+	}
+	return rows
+}
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{9, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+	}
+	for _, test := range tests {
+		got := pixels(orient(numbered(), test.orientation))
+		if !equalRows(got, test.want) {
+			t.Errorf("orientation %d: got %v, want %v", test.orientation, got, test.want)
+		}
+	}
+}
+
+func equalRows(a, b [][]uint8) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if string(a[i]) != string(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	soi := []byte{0xFF, 0xD8}
+	sos := []byte{0xFF, markerSOS, 0, 2}
+
+	// an EXIF segment from a camera that writes little-endian TIFF
+	littleEndian := []byte{0xFF, markerAPP1, 0, 0}
+	payload := []byte("Exif\x00\x00II\x2A\x00\x08\x00\x00\x00" +
+		"\x02\x00" + // two entries
+		"\x0F\x01\x02\x00\x06\x00\x00\x00\x00\x00\x00\x00" + // the camera make, skipped
+		"\x12\x01\x03\x00\x01\x00\x00\x00\x08\x00\x00\x00") // orientation 8
+	littleEndian[3] = byte(len(payload) + 2)
+	littleEndian = append(littleEndian, payload...)
+
+	tests := []struct {
+		name string
+		data [][]byte
+		want int
+	}{
+		{"no EXIF", [][]byte{soi, sos}, 1},
+		{"big-endian", [][]byte{soi, orientationSegment(6), sos}, 6},
+		{"little-endian", [][]byte{soi, littleEndian, sos}, 8},
+		{"after the scan", [][]byte{soi, sos, orientationSegment(6)}, 1},
+	}
+	for _, test := range tests {
+		var data []byte
+		for _, part := range test.data {
+			data = append(data, part...)
+		}
+		if got := jpegOrientation(data); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/config"
-	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/images"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/migrations"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
 
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/routes"
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
 
+// images processed at a time, each taking a CPU while it runs
+const imageWorkers = 2
+
 func main() {
 	err := godotenv.Load()
 
@@ -19,15 +25,39 @@ func main() {
 		log.Fatal("Couldn't load the env file")
 	}
 
-	config.ConnectDB() // connecting to the database
+	db := config.ConnectDB() // connecting to the database
 
-	router := mux.NewRouter()
+	if err := migrations.Run(db); err != nil {
+		log.Fatal(err)
+	}
 
-	routes.AuthRoutes(router)
-	routes.PostRoutes(router)
+	posts := repositories.NewMongoPostRepository(db)
+	media := repositories.NewMongoMediaRepository(db)
+	blobs := config.NewBlobStore()
 
-	corsRouter := middlewares.CORS(router)
+	// images left half-processed by the last run are picked up again
+	pipeline := images.NewPipeline(blobs, media, posts, imageWorkers)
+	if err := pipeline.Resume(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	app := &controllers.App{
+		Posts:       posts,
+		Users:       repositories.NewMongoUserRepository(db),
+		Sessions:    repositories.NewMongoSessionRepository(db),
+		APIKeys:     repositories.NewMongoAPIKeyRepository(db),
+		Reactions:   repositories.NewMongoReactionRepository(db),
+		Comments:    repositories.NewMongoCommentRepository(db),
+		Follows:     repositories.NewMongoFollowRepository(db),
+		Collections: repositories.NewMongoCollectionRepository(db),
+		Media:       media,
+		Tokens:      repositories.NewMongoTokenRepository(db),
+		LoginEvents: repositories.NewMongoLoginEventRepository(db),
+		Mailer:      config.NewMailer(),
+		Blobs:       blobs,
+		Images:      pipeline,
+	}
 
 	log.Println("Server is running on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", corsRouter))
+	log.Fatal(http.ListenAndServe(":8080", routes.NewRouter(app)))
 }
@@ -2,37 +2,230 @@ package middlewares
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
 	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+var (
+	errMissingToken   = errors.New("Missing token")
+	errInvalidToken   = errors.New("Invalid token")
+	errInvalidPayload = errors.New("Invalid token payload")
+	errRevoked        = errors.New("Session has been revoked")
+	errExpired        = errors.New("Session has expired")
+	errRevokedKey     = errors.New("API key has been revoked")
+)
+
+// AuthMiddleware accepts requests carrying a valid access token whose session has
+// not been revoked, or a valid API key, in the "token" cookie or a Bearer header
+func AuthMiddleware(sessions repositories.SessionRepository, apiKeys repositories.APIKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, err := authenticate(r, sessions, apiKeys)
+			if err != nil {
+				writeAuthError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// a session that has ended means signing in again, unlike a bad token; any
+// other error came from a repository
+func writeAuthError(w http.ResponseWriter, err error) {
+	switch err {
+	case errRevoked, errExpired:
+		apperror.Write(w, apperror.New(apperror.SessionExpired, err.Error()))
+	case errMissingToken, errInvalidToken, errInvalidPayload, errRevokedKey:
+		apperror.Write(w, apperror.New(apperror.Unauthenticated, err.Error()))
+	default:
+		apperror.Write(w, apperror.Internal("Could not check credentials"))
+	}
+}
+
+// OptionalAuth identifies the user in the same way as AuthMiddleware, but lets
+// requests without a valid token through anonymously instead of rejecting them.
+// API keys only identify their user when they have the posts:read scope.
+func OptionalAuth(sessions repositories.SessionRepository, apiKeys repositories.APIKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ctx, err := authenticate(r, sessions, apiKeys); err == nil && hasScope(ctx, models.ScopePostsRead) {
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verifies the access token and its session, returning a context holding the
+// user's ID, session ID and role
+func authenticate(r *http.Request, sessions repositories.SessionRepository, apiKeys repositories.APIKeyRepository) (context.Context, error) {
+	tokenString := bearerToken(r)
+	if tokenString == "" {
 		cookie, err := r.Cookie("token")
 		if err != nil {
-			http.Error(w, "Missing token", http.StatusUnauthorized)
-			return
+			return nil, errMissingToken
 		}
+		tokenString = cookie.Value
+	}
 
-		tokenString := cookie.Value
-		claims := jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return utils.JwtSecretKey, nil
-		})
+	if strings.HasPrefix(tokenString, utils.APIKeyPrefix) {
+		return authenticateAPIKey(r, apiKeys, tokenString)
+	}
 
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errInvalidToken
+		}
+		return utils.JwtSecretKey, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errInvalidToken
+	}
+
+	userID, ok := claims["userID"].(string)
+	if !ok {
+		return nil, errInvalidPayload
+	}
+
+	// tokens issued before sessions existed carry no session and are refused
+	sessionID, _ := claims["sid"].(string)
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, errInvalidPayload
+	}
+
+	session, err := sessions.FindByID(r.Context(), objectID)
+	if err == repositories.ErrNotFound || (err == nil && session.UserID.Hex() != userID) {
+		return nil, errRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active(time.Now().Unix()) {
+		if session.RevokedAt != 0 {
+			return nil, errRevoked
 		}
+		return nil, errExpired
+	}
 
-		userID, ok := claims["userID"].(string)
-		if !ok {
-			http.Error(w, "Invalid token payload", http.StatusUnauthorized)
+	role, _ := claims["role"].(string)
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	ctx := context.WithValue(r.Context(), "userID", userID)
+	ctx = context.WithValue(ctx, "sessionID", sessionID)
+	ctx = context.WithValue(ctx, "role", role)
+	return ctx, nil
+}
+
+// API keys act with the user role and only within their scopes
+func authenticateAPIKey(r *http.Request, apiKeys repositories.APIKeyRepository, apiKey string) (context.Context, error) {
+	keyID, ok := utils.APIKeyID(apiKey)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	objectID, err := primitive.ObjectIDFromHex(keyID)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	key, err := apiKeys.FindByID(r.Context(), objectID)
+	if err != nil && err != repositories.ErrNotFound {
+		return nil, err
+	}
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(utils.HashToken(apiKey))) != 1 {
+		return nil, errInvalidToken
+	}
+	if key.RevokedAt != 0 {
+		return nil, errRevokedKey
+	}
+
+	// recording every single use would turn each read into a write
+	if time.Now().Unix()-key.LastUsedAt >= 60 {
+		apiKeys.Touch(r.Context(), key.ID)
+	}
+
+	ctx := context.WithValue(r.Context(), "userID", key.UserID.Hex())
+	ctx = context.WithValue(ctx, "apiKeyID", key.ID.Hex())
+	ctx = context.WithValue(ctx, "scopes", key.Scopes)
+	ctx = context.WithValue(ctx, "role", models.RoleUser)
+	return ctx, nil
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// reports whether the request may act within scope; only API keys are limited to scopes
+func hasScope(ctx context.Context, scope string) bool {
+	scopes, ok := ctx.Value("scopes").([]string)
+	if !ok {
+		return true
+	}
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope refuses API keys that were not granted scope; it must be used after AuthMiddleware
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasScope(r.Context(), scope) {
+				apperror.Write(w, apperror.New(apperror.MissingScope, "API key is missing the "+scope+" scope"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession refuses API keys, for account management that only a signed-in
+// user should do; it must be used after AuthMiddleware
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value("apiKeyID") != nil {
+			apperror.Write(w, apperror.New(apperror.APIKeyNotAllowed, "API keys cannot be used here"))
 			return
 		}
-		ctx := context.WithValue(r.Context(), "userID", userID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireRole accepts requests from users with one of the given roles; it must
+// be used after AuthMiddleware, which puts the role from the token in the context
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value("role").(string)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			apperror.Write(w, apperror.New(apperror.Forbidden, "Forbidden"))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// a session store that is down
+type failingSessions struct {
+	repositories.SessionRepository
+}
+
+func (failingSessions) FindByID(ctx context.Context, id primitive.ObjectID) (models.Session, error) {
+	return models.Session{}, errors.New("connection refused")
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	utils.JwtSecretKey = []byte("test secret")
+	ctx := context.Background()
+	now := time.Now().Unix()
+	userID := primitive.NewObjectID()
+
+	sessions := repositories.NewMemorySessionRepository()
+	newSession := func(session models.Session) string {
+		session.ID = primitive.NewObjectID()
+		session.UserID = userID
+		if err := sessions.Create(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+		token, err := utils.GenerateJWT(userID.Hex(), "Amina", models.RoleUser, session.ID.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	active := newSession(models.Session{ExpiresAt: now + 3600})
+	expired := newSession(models.Session{ExpiresAt: now - 1})
+	revoked := newSession(models.Session{ExpiresAt: now + 3600, RevokedAt: now})
+	unknown, _ := utils.GenerateJWT(userID.Hex(), "Amina", models.RoleUser, primitive.NewObjectID().Hex())
+
+	tests := []struct {
+		name     string
+		sessions repositories.SessionRepository
+		token    string
+		status   int
+		code     string
+	}{
+		{"active session", sessions, active, http.StatusOK, ""},
+		{"no token", sessions, "", http.StatusUnauthorized, "UNAUTHENTICATED"},
+		{"malformed token", sessions, "not.a.token", http.StatusUnauthorized, "UNAUTHENTICATED"},
+		{"expired session", sessions, expired, http.StatusUnauthorized, "SESSION_EXPIRED"},
+		{"revoked session", sessions, revoked, http.StatusUnauthorized, "SESSION_EXPIRED"},
+		{"unknown session", sessions, unknown, http.StatusUnauthorized, "SESSION_EXPIRED"},
+		{"session store down", failingSessions{}, active, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("{}")) })
+			handler := AuthMiddleware(test.sessions, repositories.NewMemoryAPIKeyRepository())(ok)
+
+			req := httptest.NewRequest("GET", "/users/me", nil)
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if rec.Code != test.status || body.Error.Code != test.code {
+				t.Errorf("got %d %q, want %d %q", rec.Code, body.Error.Code, test.status, test.code)
+			}
+		})
+	}
+}
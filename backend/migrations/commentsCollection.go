@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// moves the comments embedded in posts into the comments collection as top-level
+// comments, and stores how many each post had
+func commentsCollection(ctx context.Context, db *mongo.Database) error {
+	posts := db.Collection("posts")
+	comments := db.Collection("comments")
+
+	cursor, err := posts.Find(ctx, bson.M{"comments": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Comments []models.Comment   `bson:"comments"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+
+		// comments without an ID are given one on the post first, so that a run
+		// cut short can be repeated without copying them twice
+		assigned := false
+		for i := range legacy.Comments {
+			if legacy.Comments[i].ID.IsZero() {
+				legacy.Comments[i].ID = primitive.NewObjectID()
+				assigned = true
+			}
+		}
+		if assigned {
+			_, err := posts.UpdateOne(ctx, bson.M{"_id": legacy.ID}, bson.M{"$set": bson.M{"comments": legacy.Comments}})
+			if err != nil {
+				return err
+			}
+		}
+
+		var writes []mongo.WriteModel
+		for _, comment := range legacy.Comments {
+			comment.PostID = legacy.ID
+			comment.RootID = comment.ID
+			comment.ParentID = nil
+			writes = append(writes, mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"_id": comment.ID}).
+				SetReplacement(comment).
+				SetUpsert(true))
+		}
+
+		if len(writes) > 0 {
+			if _, err := comments.BulkWrite(ctx, writes); err != nil {
+				return err
+			}
+		}
+
+		_, err = posts.UpdateOne(ctx, bson.M{"_id": legacy.ID}, bson.M{
+			"$unset": bson.M{"comments": ""},
+			"$set":   bson.M{"commentCount": len(writes)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// stores the countries of posts and country follows under the names
+// CanonicalCountry gives them, so "pk" and "pakistan" list together with
+// "Pakistan". Names it doesn't know are left as they are
+func countryNames(ctx context.Context, db *mongo.Database) error {
+	posts := db.Collection("posts")
+
+	names, err := posts.Distinct(ctx, "country", bson.M{})
+	if err != nil {
+		return err
+	}
+	unknown := 0
+	for _, value := range names {
+		name, _ := value.(string)
+		canonical, ok := models.CanonicalCountry(name)
+		if !ok {
+			unknown++
+			continue
+		}
+		if canonical == name {
+			continue
+		}
+		if _, err := posts.UpdateMany(ctx, bson.M{"country": name}, bson.M{"$set": bson.M{"country": canonical}}); err != nil {
+			return err
+		}
+	}
+	if unknown > 0 {
+		log.Printf("Left %d country names that are not known countries", unknown)
+	}
+
+	return countryFollows(ctx, db.Collection("follows"))
+}
+
+// follows are unique per follower and country, so a follow of "pk" is dropped
+// when the same user follows "Pakistan" already
+func countryFollows(ctx context.Context, follows *mongo.Collection) error {
+	cursor, err := follows.Find(ctx, bson.M{"kind": models.FollowCountry})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var follow struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Target string             `bson:"target"`
+		}
+		if err := cursor.Decode(&follow); err != nil {
+			return err
+		}
+
+		canonical, ok := models.CanonicalCountry(follow.Target)
+		if !ok || canonical == follow.Target {
+			continue
+		}
+
+		_, err := follows.UpdateOne(ctx, bson.M{"_id": follow.ID}, bson.M{"$set": bson.M{"target": canonical}})
+		if mongo.IsDuplicateKeyError(err) {
+			_, err = follows.DeleteOne(ctx, bson.M{"_id": follow.ID})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type migration struct {
+	name string
+	run  func(ctx context.Context, db *mongo.Database) error
+}
+
+// every migration ever written, in the order they must be applied
+var all = []migration{
+	{name: "001_structured_recipes", run: structuredRecipes},
+	{name: "002_reactions_collection", run: reactionsCollection},
+	{name: "003_comments_collection", run: commentsCollection},
+	{name: "004_user_roles", run: userRoles},
+	{name: "005_user_profiles", run: userProfiles},
+	{name: "006_video_links", run: videoLinks},
+	{name: "007_country_names", run: countryNames},
+}
+
+// Run applies the migrations that have not been recorded in the "migrations" collection yet
+func Run(db *mongo.Database) error {
+	ctx := context.Background()
+	applied := db.Collection("migrations")
+
+	for _, m := range all {
+		count, err := applied.CountDocuments(ctx, bson.M{"_id": m.name})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		log.Printf("Running migration %s", m.name)
+		if err := m.run(ctx, db); err != nil {
+			return err
+		}
+
+		_, err = applied.InsertOne(ctx, bson.M{"_id": m.name, "appliedAt": time.Now().Unix()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// moves the likesList and dislikeList arrays kept on users into the reactions
+// collection, then recomputes every post's counters from it
+func reactionsCollection(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	reactions := repositories.NewMongoReactionRepository(db)
+
+	cursor, err := users.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"likesList.0": bson.M{"$exists": true}},
+		bson.M{"dislikeList.0": bson.M{"$exists": true}},
+	}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	now := time.Now().Unix()
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID          primitive.ObjectID   `bson:"_id"`
+			LikesList   []primitive.ObjectID `bson:"likesList"`
+			DislikeList []primitive.ObjectID `bson:"dislikeList"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+
+		for _, postID := range legacy.LikesList {
+			reaction := models.Reaction{UserID: legacy.ID, PostID: postID, Kind: models.ReactionLike, CreatedAt: now}
+			if _, err := reactions.Add(ctx, reaction); err != nil {
+				return err
+			}
+		}
+		for _, postID := range legacy.DislikeList {
+			reaction := models.Reaction{UserID: legacy.ID, PostID: postID, Kind: models.ReactionDislike, CreatedAt: now}
+			if _, err := reactions.Add(ctx, reaction); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	_, err = users.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"likesList": "", "dislikeList": ""}})
+	if err != nil {
+		return err
+	}
+
+	return repositories.ReconcileReactionCounts(ctx, db)
+}
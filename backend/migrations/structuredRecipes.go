@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// parses the comma-joined recipe strings written by the old CreatePost form
+// into the structured recipe shape
+func structuredRecipes(ctx context.Context, db *mongo.Database) error {
+	posts := db.Collection("posts")
+
+	cursor, err := posts.Find(ctx, bson.M{"recipe": bson.M{"$type": "string"}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Recipe string             `bson:"recipe"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+
+		recipe := models.ParseRecipeText(legacy.Recipe)
+		_, err := posts.UpdateOne(ctx, bson.M{"_id": legacy.ID}, bson.M{"$set": bson.M{"recipe": recipe}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
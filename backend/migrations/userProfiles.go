@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// gives every existing account a handle made from its name, and a join date
+// taken from when its ID was generated
+func userProfiles(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+
+	cursor, err := users.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"handle": bson.M{"$exists": false}},
+		bson.M{"createdAt": bson.M{"$exists": false}},
+	}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	taken := func(handle string) (bool, error) {
+		count, err := users.CountDocuments(ctx, bson.M{"handle": handle})
+		return count > 0, err
+	}
+
+	for cursor.Next(ctx) {
+		var user struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Name      string             `bson:"name"`
+			Handle    string             `bson:"handle"`
+			CreatedAt int64              `bson:"createdAt"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		set := bson.M{}
+		if user.Handle == "" {
+			handle, err := utils.UniqueHandle(utils.HandleFromName(user.Name), taken)
+			if err != nil {
+				return err
+			}
+			set["handle"] = handle
+		}
+		if user.CreatedAt == 0 {
+			set["createdAt"] = user.ID.Timestamp().Unix()
+		}
+
+		if _, err := users.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// gives every existing account the user role
+func userRoles(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"role": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"role": models.RoleUser}},
+	)
+	return err
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// normalizes the video links saved before they were checked. Links that can't
+// be embedded are taken off the post, and kept under invalid_video_url so that
+// nothing is lost
+func videoLinks(ctx context.Context, db *mongo.Database) error {
+	posts := db.Collection("posts")
+
+	cursor, err := posts.Find(ctx, bson.M{"video_url": bson.M{"$nin": bson.A{"", nil}}, "video": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	invalid := 0
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			VideoURL string             `bson:"video_url"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"video_url": "", "invalid_video_url": legacy.VideoURL}}
+		if video, canonical, err := models.ParseVideoURL(legacy.VideoURL); err == nil {
+			update = bson.M{"$set": bson.M{"video_url": canonical, "video": video}}
+		} else {
+			invalid++
+		}
+
+		if _, err := posts.UpdateOne(ctx, bson.M{"_id": legacy.ID}, update); err != nil {
+			return err
+		}
+	}
+
+	if invalid > 0 {
+		log.Printf("Removed %d video links that could not be embedded", invalid)
+	}
+	return cursor.Err()
+}
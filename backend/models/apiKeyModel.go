@@ -0,0 +1,27 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// scopes an API key can be granted
+const (
+	ScopePostsRead     = "posts:read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+)
+
+// IsScope reports whether scope is one of the known API key scopes
+func IsScope(scope string) bool {
+	return scope == ScopePostsRead || scope == ScopePostsWrite || scope == ScopeCommentsWrite
+}
+
+// APIKey is a personal key for scripts and apps; only its hash is stored
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"-" bson:"userID"`
+	Name       string             `json:"name" bson:"name"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	Hash       string             `json:"-" bson:"hash"`
+	CreatedAt  int64              `json:"createdAt" bson:"createdAt"`
+	LastUsedAt int64              `json:"lastUsedAt" bson:"lastUsedAt,omitempty"`
+	RevokedAt  int64              `json:"-" bson:"revokedAt,omitempty"`
+}
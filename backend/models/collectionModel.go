@@ -0,0 +1,23 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Collection is a user's cookbook of saved posts, kept in the order the user chose
+type Collection struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"userID" bson:"userID"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description,omitempty"`
+	// Public collections can be seen by anyone with the link
+	Public    bool             `json:"public" bson:"public"`
+	Items     []CollectionItem `json:"-" bson:"items"`
+	CreatedAt int64            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt int64            `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CollectionItem is a post saved in a collection, with the user's own note on it
+type CollectionItem struct {
+	PostID  primitive.ObjectID `json:"postID" bson:"postID"`
+	Note    string             `json:"note" bson:"note,omitempty"`
+	AddedAt int64              `json:"addedAt" bson:"addedAt"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// shown instead of the text of a comment that has been deleted
+const DeletedCommentText = "[deleted]"
+
+// shown instead of the text of a comment hidden by a moderator
+const HiddenCommentText = "[removed by a moderator]"
+
+type Comment struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty"`
+	PostID   primitive.ObjectID  `bson:"postID"`
+	ParentID *primitive.ObjectID `bson:"parentID,omitempty"`
+	// RootID is the top-level comment of the thread, which is the comment itself for top-level comments
+	RootID    primitive.ObjectID `bson:"rootID"`
+	UserID    primitive.ObjectID `bson:"userID,omitempty"`
+	Name      string             `bson:"name,omitempty"`
+	Text      string             `bson:"text,omitempty" validate:"required,max=2000"`
+	Created   int64              `bson:"created,omitempty"`
+	UpdatedAt int64              `bson:"updatedAt,omitempty"`
+	Deleted   bool               `bson:"deleted,omitempty"`
+	Hidden    bool               `bson:"hidden,omitempty"`
+}
+
+// CommentThread is a comment together with the replies made to it
+type CommentThread struct {
+	Comment
+	Replies []CommentThread
+}
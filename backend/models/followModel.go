@@ -0,0 +1,19 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// what a user can follow
+const (
+	FollowUser    = "user"
+	FollowCountry = "country"
+)
+
+// Follow is a user following a cook or a country; Target holds the cook's ID
+// in hex or the country's name
+type Follow struct {
+	ID         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	FollowerID primitive.ObjectID `json:"-" bson:"followerID"`
+	Kind       string             `json:"kind" bson:"kind"`
+	Target     string             `json:"target" bson:"target"`
+	CreatedAt  int64              `json:"createdAt" bson:"createdAt"`
+}
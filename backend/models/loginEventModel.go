@@ -0,0 +1,22 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// why a sign-in attempt failed
+const (
+	LoginInvalidPassword = "invalid_password"
+	LoginInvalidCode     = "invalid_code"
+	LoginLocked          = "locked"
+	LoginThrottled       = "throttled"
+)
+
+// LoginEvent is one attempt to sign in to an account, kept as its audit trail
+type LoginEvent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"-" bson:"userID"`
+	Success   bool               `json:"success" bson:"success"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	IP        string             `json:"ip" bson:"ip"`
+	UserAgent string             `json:"userAgent" bson:"userAgent"`
+	CreatedAt int64              `json:"createdAt" bson:"createdAt"`
+}
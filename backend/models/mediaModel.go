@@ -0,0 +1,72 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// kinds of uploaded media
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// states of the resized variants of an uploaded image
+const (
+	MediaPending = "pending"
+	MediaReady   = "ready"
+	MediaFailed  = "failed"
+)
+
+// Media is a file a user has uploaded, ready to be attached to their posts
+type Media struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"-" bson:"userID"`
+	Kind        string             `json:"kind" bson:"kind"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Size        int64              `json:"size" bson:"size"`
+	// Key is where the file is kept in the blob store
+	Key       string `json:"-" bson:"key"`
+	URL       string `json:"url" bson:"url"`
+	CreatedAt int64  `json:"createdAt" bson:"createdAt"`
+	// Status tells whether the variants of an image have been made yet; videos have none
+	Status       string `json:"status,omitempty" bson:"status,omitempty"`
+	ImageDetails `bson:",inline"`
+}
+
+// ImageDetails is what processing an uploaded image finds out about it
+type ImageDetails struct {
+	Width  int `json:"width,omitempty" bson:"width,omitempty"`
+	Height int `json:"height,omitempty" bson:"height,omitempty"`
+	// Blurhash is a compact placeholder to show while the image loads
+	Blurhash string `json:"blurhash,omitempty" bson:"blurhash,omitempty"`
+	// Color is the dominant color, as "#rrggbb"
+	Color    string         `json:"color,omitempty" bson:"color,omitempty"`
+	Variants []MediaVariant `json:"variants,omitempty" bson:"variants,omitempty"`
+}
+
+// names of the sizes images are resized to
+const (
+	VariantThumbnail = "thumbnail"
+	VariantCard      = "card"
+	VariantHero      = "hero"
+)
+
+// MediaVariant is a resized copy of an image, encoded both as WebP and, for
+// clients without WebP support, as JPEG
+type MediaVariant struct {
+	Name   string `json:"name" bson:"name"`
+	Width  int    `json:"width" bson:"width"`
+	Height int    `json:"height" bson:"height"`
+	WebP   string `json:"webp" bson:"webp"`
+	JPEG   string `json:"jpeg" bson:"jpeg"`
+}
+
+// PostMedia is an uploaded file attached to a post; posts list them in the
+// order they are shown. Only the ID and alt text come from the client, the
+// rest is copied from the upload
+type PostMedia struct {
+	ID           primitive.ObjectID `json:"id" bson:"id"`
+	Kind         string             `json:"kind" bson:"kind"`
+	URL          string             `json:"url" bson:"url"`
+	ContentType  string             `json:"contentType" bson:"contentType"`
+	Alt          string             `json:"alt" bson:"alt,omitempty" validate:"max=250"`
+	ImageDetails `bson:",inline"`
+}
@@ -5,23 +5,47 @@ import (
 )
 
 type Post struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	UserID      primitive.ObjectID `bson:"userID,omitempty"`
-	Title       string             `bson:"title,omitempty"`
-	Description string             `bson:"description,omitempty"`
-	VideoURL    string             `json:"video_url" bson:"video_url"`
-	Recipe      string             `bson:"recipe,omitempty"`
-	Country     string             `bson:"country,omitempty"`
-	Likes       int                `bson:"likes,omitempty"`
-	Dislikes    int                `bson:"dislikes,omitempty"`
-	Comments    []Comment          `bson:"comments,omitempty"`
-	CreatedAt   int64              `bson:"createdAt,omitempty"`
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `bson:"userID,omitempty"`
+	AuthorName   string             `bson:"authorName,omitempty"`
+	Title        string             `bson:"title,omitempty" validate:"required,max=120"`
+	Description  string             `bson:"description,omitempty" validate:"max=5000"`
+	VideoURL     string             `json:"video_url" bson:"video_url" validate:"omitempty,max=2048,http_url"`
+	Video        *Video             `json:"video,omitempty" bson:"video,omitempty"`
+	Media        []PostMedia        `json:"media" bson:"media,omitempty" validate:"max=10,dive"`
+	Recipe       Recipe             `bson:"recipe"`
+	Country      string             `bson:"country,omitempty" validate:"required,country"`
+	Likes        int                `bson:"likes,omitempty"`
+	Dislikes     int                `bson:"dislikes,omitempty"`
+	Reactions    map[string]int     `bson:"reactions,omitempty"`
+	CommentCount int                `bson:"commentCount"`
+	CreatedAt    int64              `bson:"createdAt,omitempty"`
+	UpdatedAt    int64              `bson:"updatedAt,omitempty"`
+	Hidden       bool               `bson:"hidden,omitempty"`
 }
 
-type Comment struct {
-	ID      primitive.ObjectID `bson:"_id,omitempty"`
-	UserID  primitive.ObjectID `bson:"userID,omitempty"`
-	Name    string             `bson:"name,omitempty"`
-	Text    string             `bson:"text,omitempty"`
-	Created int64              `bson:"created,omitempty"`
+// shown in place of the author's name when their account no longer exists
+const DeletedUserName = "Deleted user"
+
+// PostSummary is the shape posts take in listings and search results,
+// with the author's name and avatar looked up alongside the post
+type PostSummary struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	UserID       primitive.ObjectID `bson:"userID"`
+	UserName     string             `bson:"userName"`
+	UserAvatar   string             `bson:"userAvatar"`
+	Title        string             `bson:"title"`
+	Description  string             `bson:"description"`
+	VideoURL     string             `json:"video_url" bson:"video_url"`
+	Video        *Video             `json:"video,omitempty" bson:"video,omitempty"`
+	Media        []PostMedia        `json:"media" bson:"media"`
+	Country      string             `bson:"country"`
+	Ingredients  []string           `bson:"ingredients"`
+	Likes        int                `bson:"likes"`
+	Dislikes     int                `bson:"dislikes"`
+	Reactions    map[string]int     `bson:"reactions"`
+	CommentCount int                `bson:"commentCount"`
+	CreatedAt    int64              `bson:"createdAt"`
+	// MyReactions is filled in for signed-in users only
+	MyReactions []string `json:"myReactions,omitempty" bson:"-"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+// a like and a dislike cancel each other out; every other kind can be combined freely
+func OppositeReaction(kind string) string {
+	switch kind {
+	case ReactionLike:
+		return ReactionDislike
+	case ReactionDislike:
+		return ReactionLike
+	default:
+		return ""
+	}
+}
+
+type Reaction struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"userID" bson:"userID"`
+	PostID    primitive.ObjectID `json:"postID" bson:"postID"`
+	Kind      string             `json:"kind" bson:"kind"`
+	CreatedAt int64              `json:"createdAt" bson:"createdAt"`
+}
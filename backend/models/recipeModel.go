@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type Recipe struct {
+	Servings    int          `json:"servings" bson:"servings,omitempty" validate:"omitempty,min=1,max=100"`
+	PrepMinutes int          `json:"prepMinutes" bson:"prepMinutes,omitempty" validate:"min=0,max=10080"`
+	CookMinutes int          `json:"cookMinutes" bson:"cookMinutes,omitempty" validate:"min=0,max=10080"`
+	Ingredients []Ingredient `json:"ingredients" bson:"ingredients" validate:"required,min=1,max=100,dive"`
+	Steps       []Step       `json:"steps" bson:"steps" validate:"max=100,dive"`
+}
+
+type Ingredient struct {
+	Quantity float64 `json:"quantity" bson:"quantity,omitempty" validate:"min=0,max=100000"`
+	Unit     string  `json:"unit" bson:"unit,omitempty" validate:"omitempty,unit"`
+	Name     string  `json:"name" bson:"name" validate:"required,max=100"`
+	Notes    string  `json:"notes" bson:"notes,omitempty" validate:"max=200"`
+}
+
+type Step struct {
+	Text            string `json:"text" bson:"text" validate:"required,max=2000"`
+	DurationMinutes int    `json:"durationMinutes" bson:"durationMinutes,omitempty" validate:"min=0,max=10080"`
+}
+
+// UnmarshalJSON also accepts the old comma-separated recipe string, so clients
+// that still send "rice, chicken, saffron" keep working
+func (recipe *Recipe) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*recipe = ParseRecipeText(text)
+		return nil
+	}
+
+	type plainRecipe Recipe
+	return json.Unmarshal(data, (*plainRecipe)(recipe))
+}
+
+// SearchText returns the words of the recipe that are worth searching on
+func (recipe Recipe) SearchText() string {
+	var parts []string
+	for _, ingredient := range recipe.Ingredients {
+		parts = append(parts, ingredient.Name)
+	}
+	for _, step := range recipe.Steps {
+		parts = append(parts, step.Text)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maps the spellings people use for units to the canonical unit names
+var unitAliases = map[string]string{
+	"tsp": "tsp", "teaspoon": "tsp", "teaspoons": "tsp",
+	"tbsp": "tbsp", "tablespoon": "tbsp", "tablespoons": "tbsp", "tbs": "tbsp", "tbl": "tbsp",
+	"cup": "cup", "cups": "cup",
+	"ml": "ml", "milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"l": "l", "liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"floz": "fl oz", "fl oz": "fl oz", "fluid ounce": "fl oz", "fluid ounces": "fl oz",
+	"g": "g", "gram": "g", "grams": "g", "gm": "g", "gms": "g",
+	"kg": "kg", "kilogram": "kg", "kilograms": "kg", "kgs": "kg",
+	"oz": "oz", "ounce": "oz", "ounces": "oz",
+	"lb": "lb", "lbs": "lb", "pound": "lb", "pounds": "lb",
+	"pinch": "pinch", "pinches": "pinch",
+	"clove": "clove", "cloves": "clove",
+	"piece": "piece", "pieces": "piece", "pc": "piece", "pcs": "piece",
+	"can": "can", "cans": "can",
+	"bunch": "bunch", "bunches": "bunch",
+	"slice": "slice", "slices": "slice",
+}
+
+var unicodeFractions = map[rune]float64{
+	'¼': 0.25, '½': 0.5, '¾': 0.75,
+	'⅓': 1.0 / 3, '⅔': 2.0 / 3,
+	'⅛': 0.125, '⅜': 0.375, '⅝': 0.625, '⅞': 0.875,
+}
+
+// IsKnownUnit reports whether unit is one of the canonical unit names
+func IsKnownUnit(unit string) bool {
+	for _, canonical := range unitAliases {
+		if canonical == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeUnit returns the canonical name for a unit spelling, or "" if it is not a unit
+func NormalizeUnit(unit string) string {
+	return unitAliases[strings.TrimSuffix(strings.ToLower(strings.TrimSpace(unit)), ".")]
+}
+
+// ParseRecipeText turns the old comma-separated recipe string into a structured recipe
+func ParseRecipeText(text string) Recipe {
+	recipe := Recipe{Ingredients: []Ingredient{}, Steps: []Step{}}
+
+	lines := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' })
+	for _, line := range lines {
+		if ingredient, ok := ParseIngredient(line); ok {
+			recipe.Ingredients = append(recipe.Ingredients, ingredient)
+		}
+	}
+
+	return recipe
+}
+
+// ParseIngredient reads lines such as "1 1/2 cups basmati rice (washed)"
+func ParseIngredient(line string) (Ingredient, bool) {
+	var ingredient Ingredient
+
+	line = strings.TrimSpace(line)
+	if open := strings.Index(line, "("); open >= 0 && strings.HasSuffix(line, ")") {
+		ingredient.Notes = strings.TrimSpace(line[open+1 : len(line)-1])
+		line = strings.TrimSpace(line[:open])
+	}
+
+	words := strings.Fields(line)
+
+	// split quantities written together with their unit, as in "500g"
+	if len(words) > 0 {
+		if split := strings.IndexFunc(words[0], unicode.IsLetter); split > 0 && NormalizeUnit(words[0][split:]) != "" {
+			words = append([]string{words[0][:split], words[0][split:]}, words[1:]...)
+		}
+	}
+
+	// a quantity can be spread over two words, as in "1 1/2"
+	for len(words) > 0 {
+		value, ok := parseQuantity(words[0])
+		if !ok {
+			break
+		}
+		ingredient.Quantity += value
+		words = words[1:]
+	}
+
+	if len(words) > 1 {
+		if unit := NormalizeUnit(words[0] + " " + words[1]); unit != "" {
+			ingredient.Unit = unit
+			words = words[2:]
+		}
+	}
+	if ingredient.Unit == "" && len(words) > 1 {
+		if unit := NormalizeUnit(words[0]); unit != "" {
+			ingredient.Unit = unit
+			words = words[1:]
+		}
+	}
+
+	if len(words) > 1 && strings.EqualFold(words[0], "of") {
+		words = words[1:]
+	}
+
+	ingredient.Name = strings.Join(words, " ")
+	if ingredient.Name == "" {
+		return ingredient, false
+	}
+
+	return ingredient, true
+}
+
+// parses "2", "0.5", "1/2", "½", "1½" and ranges like "2-3" (using the lower bound)
+func parseQuantity(word string) (float64, bool) {
+	if word == "" || !strings.ContainsAny(word[:1], "0123456789.") && !strings.ContainsAny(word, "¼½¾⅓⅔⅛⅜⅝⅞") {
+		return 0, false
+	}
+
+	if dash := strings.Index(word, "-"); dash > 0 {
+		word = word[:dash]
+	}
+
+	var value float64
+	runes := []rune(word)
+	if fraction, ok := unicodeFractions[runes[len(runes)-1]]; ok {
+		value = fraction
+		word = string(runes[:len(runes)-1])
+		if word == "" {
+			return value, true
+		}
+	}
+
+	if slash := strings.Index(word, "/"); slash > 0 {
+		numerator, err1 := strconv.ParseFloat(word[:slash], 64)
+		denominator, err2 := strconv.ParseFloat(word[slash+1:], 64)
+		if err1 != nil || err2 != nil || denominator == 0 {
+			return 0, false
+		}
+		return value + numerator/denominator, true
+	}
+
+	number, err := strconv.ParseFloat(word, 64)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+	return value + number, true
+}
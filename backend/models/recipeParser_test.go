@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line string
+		want Ingredient
+	}{
+		{"1 1/2 cups basmati rice (washed)", Ingredient{Quantity: 1.5, Unit: "cup", Name: "basmati rice", Notes: "washed"}},
+		{"500g chicken", Ingredient{Quantity: 500, Unit: "g", Name: "chicken"}},
+		{"½ tsp salt", Ingredient{Quantity: 0.5, Unit: "tsp", Name: "salt"}},
+		{"1½ cups sugar", Ingredient{Quantity: 1.5, Unit: "cup", Name: "sugar"}},
+		{"2-3 tomatoes", Ingredient{Quantity: 2, Name: "tomatoes"}},
+		{"2 fl oz cream", Ingredient{Quantity: 2, Unit: "fl oz", Name: "cream"}},
+		{"2 Tablespoons. of ghee", Ingredient{Quantity: 2, Unit: "tbsp", Name: "ghee"}},
+		{"3 eggs", Ingredient{Quantity: 3, Name: "eggs"}},
+		{"  saffron  ", Ingredient{Name: "saffron"}},
+		{"pinch of salt", Ingredient{Unit: "pinch", Name: "salt"}},
+	}
+	for _, test := range tests {
+		got, ok := ParseIngredient(test.line)
+		if !ok || got != test.want {
+			t.Errorf("ParseIngredient(%q) = %+v, %v; want %+v", test.line, got, ok, test.want)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "2", "(chopped)"} {
+		if got, ok := ParseIngredient(line); ok {
+			t.Errorf("ParseIngredient(%q) = %+v, want no ingredient", line, got)
+		}
+	}
+}
+
+func TestParseRecipeText(t *testing.T) {
+	got := ParseRecipeText("1 kg chicken, 2 onions,\n 1 tsp cumin,, ")
+	want := Recipe{
+		Ingredients: []Ingredient{
+			{Quantity: 1, Unit: "kg", Name: "chicken"},
+			{Quantity: 2, Name: "onions"},
+			{Quantity: 1, Unit: "tsp", Name: "cumin"},
+		},
+		Steps: []Step{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRecipeText = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecipeUnmarshalJSON(t *testing.T) {
+	var legacy Recipe
+	if err := json.Unmarshal([]byte(`"rice, 2 cups water"`), &legacy); err != nil {
+		t.Fatalf("legacy string: %v", err)
+	}
+	if len(legacy.Ingredients) != 2 || legacy.Ingredients[1] != (Ingredient{Quantity: 2, Unit: "cup", Name: "water"}) {
+		t.Errorf("legacy string parsed as %+v", legacy)
+	}
+
+	var structured Recipe
+	err := json.Unmarshal([]byte(`{"servings":4,"ingredients":[{"quantity":1,"unit":"kg","name":"chicken"}],"steps":[{"text":"Fry","durationMinutes":10}]}`), &structured)
+	if err != nil {
+		t.Fatalf("structured recipe: %v", err)
+	}
+	if structured.Servings != 4 || len(structured.Ingredients) != 1 || structured.Steps[0].DurationMinutes != 10 {
+		t.Errorf("structured recipe parsed as %+v", structured)
+	}
+}
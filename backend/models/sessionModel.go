@@ -0,0 +1,23 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Session is a signed-in device; its refresh token is stored only as a hash
+type Session struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"-" bson:"userID"`
+	RefreshHash string             `json:"-" bson:"refreshHash"`
+	// PreviousHash is the refresh token rotated away last, kept to spot it being replayed
+	PreviousHash string `json:"-" bson:"previousHash,omitempty"`
+	UserAgent    string `json:"userAgent" bson:"userAgent"`
+	IP           string `json:"ip" bson:"ip"`
+	CreatedAt    int64  `json:"createdAt" bson:"createdAt"`
+	LastUsedAt   int64  `json:"lastUsedAt" bson:"lastUsedAt"`
+	ExpiresAt    int64  `json:"expiresAt" bson:"expiresAt"`
+	RevokedAt    int64  `json:"-" bson:"revokedAt,omitempty"`
+}
+
+// Active reports whether the session can still be used at the given unix time
+func (session Session) Active(now int64) bool {
+	return session.RevokedAt == 0 && now < session.ExpiresAt
+}
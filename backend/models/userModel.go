@@ -1,12 +1,89 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// roles a user can have, each allowed everything the ones before it are
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// IsRole reports whether role is one of the known roles
+func IsRole(role string) bool {
+	return role == RoleUser || role == RoleModerator || role == RoleAdmin
+}
 
 type User struct {
-	ID          primitive.ObjectID   `bson:"_id,omitempty"`
-	Name        string               `json:"name" bson:"name" validate:"required"`
-	Email       string               `json:"email" bson:"email" validate:"required,email"`
-	Password    string               `json:"password" bson:"password" validate:"required,min=8,containsany=!@#$%^&*(),containsany=0123456789"`
-	LikesList   []primitive.ObjectID `json:"likesList" bson:"likesList"`
-	DislikeList []primitive.ObjectID `json:"dislikeList" bson:"dislikeList"`
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Name          string             `json:"name" bson:"name" validate:"required"`
+	Email         string             `json:"email" bson:"email" validate:"required,email"`
+	Password      string             `json:"password" bson:"password" validate:"required,min=8,containsany=!@#$%^&*(),containsany=0123456789"`
+	Avatar        string             `json:"avatar" bson:"avatar,omitempty"`
+	Handle        string             `json:"handle" bson:"handle,omitempty"`
+	Bio           string             `json:"bio" bson:"bio,omitempty"`
+	Country       string             `json:"country" bson:"country,omitempty"`
+	CreatedAt     int64              `json:"createdAt" bson:"createdAt,omitempty"`
+	Role          string             `json:"role" bson:"role"`
+	TwoFactor     TwoFactor          `json:"-" bson:"twoFactor,omitempty"`
+	EmailVerified bool               `json:"emailVerified" bson:"emailVerified"`
+	// FailedLogins counts the failed sign-ins since the last successful one
+	FailedLogins int   `json:"-" bson:"failedLogins,omitempty"`
+	LockedUntil  int64 `json:"-" bson:"lockedUntil,omitempty"`
+}
+
+// TwoFactor holds a user's TOTP settings; the secret is kept while setting up,
+// but only asked for once Enabled is set
+type TwoFactor struct {
+	Secret  string `bson:"secret,omitempty"`
+	Enabled bool   `bson:"enabled,omitempty"`
+	// RecoveryCodes are hashes of the unused one-time recovery codes
+	RecoveryCodes []string `bson:"recoveryCodes,omitempty"`
+	// LastStep is the time step of the last code used, so a code cannot be used twice
+	LastStep int64 `bson:"lastStep,omitempty"`
+}
+
+// handles are lowercase letters, digits and underscores
+var handlePattern = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// IsHandle reports whether handle can be used as a user's handle; handles that
+// could be mistaken for an ID or a route are not allowed
+func IsHandle(handle string) bool {
+	return handlePattern.MatchString(handle) && !primitive.IsValidObjectID(handle) && handle != "me"
+}
+
+// NormalizeHandle lower-cases the handle and drops a leading @
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ProfileUpdate holds the profile fields a user can change, as stored
+type ProfileUpdate struct {
+	Name    string
+	Handle  string
+	Bio     string
+	Avatar  string
+	Country string
+}
+
+// Profile is what anyone can see about a user
+type Profile struct {
+	ID            primitive.ObjectID `json:"id"`
+	Name          string             `json:"name"`
+	Handle        string             `json:"handle"`
+	Bio           string             `json:"bio"`
+	Avatar        string             `json:"avatar"`
+	Country       string             `json:"country"`
+	JoinedAt      int64              `json:"joinedAt"`
+	PostCount     int64              `json:"postCount"`
+	LikesReceived int64              `json:"likesReceived"`
+	Followers     int64              `json:"followers"`
+	Following     int64              `json:"following"`
+	// FollowedByMe is filled in for signed-in users only
+	FollowedByMe bool `json:"followedByMe"`
 }
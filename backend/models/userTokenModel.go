@@ -0,0 +1,20 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// purposes a UserToken can be issued for
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+// UserToken is a single-use token emailed to a user; only its hash is stored
+type UserToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"userID"`
+	Purpose   string             `bson:"purpose"`
+	Hash      string             `bson:"hash"`
+	CreatedAt int64              `bson:"createdAt"`
+	ExpiresAt int64              `bson:"expiresAt"`
+	UsedAt    int64              `bson:"usedAt,omitempty"`
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// video hosts a post can link to
+const (
+	VideoYouTube = "youtube"
+	VideoVimeo   = "vimeo"
+	VideoMP4     = "mp4"
+)
+
+// Video is the normalized form of a post's video link
+type Video struct {
+	Provider string `json:"provider" bson:"provider"`
+	// VideoID is empty for direct MP4 links
+	VideoID string `json:"videoID,omitempty" bson:"videoID,omitempty"`
+	// Hash is the extra key of an unlisted Vimeo video
+	Hash string `json:"-" bson:"hash,omitempty"`
+	// Start is where playback starts, in seconds
+	Start    int    `json:"start,omitempty" bson:"start,omitempty"`
+	EmbedURL string `json:"embedURL" bson:"embedURL"`
+	// ThumbnailURL is only known for YouTube; Vimeo would need a call to its API
+	ThumbnailURL string `json:"thumbnailURL,omitempty" bson:"thumbnailURL,omitempty"`
+}
+
+// errors from ParseVideoURL
+var (
+	ErrMalformedVideoURL   = errors.New("malformed video URL")
+	ErrUnsupportedVideoURL = errors.New("unsupported video host")
+	ErrMissingVideoID      = errors.New("video URL has no video ID")
+)
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]{1,12}$`)
+	vimeoHashPattern = regexp.MustCompile(`^[0-9a-f]{6,20}$`)
+	// "90", "90s", "1m30s" or "1h2m3s"
+	startPattern = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+)
+
+// ParseVideoURL recognizes YouTube, Vimeo and direct MP4 links in their common
+// forms and returns the video they point to, with the canonical link to
+// store in place of the one given
+func ParseVideoURL(raw string) (Video, string, error) {
+	link, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || link.Host == "" {
+		return Video{}, "", ErrMalformedVideoURL
+	}
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return Video{}, "", ErrUnsupportedVideoURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(link.Hostname()), "www.")
+	switch host {
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com", "youtu.be":
+		return parseYouTube(link, host)
+	case "vimeo.com", "player.vimeo.com":
+		return parseVimeo(link, host)
+	}
+
+	if strings.HasSuffix(strings.ToLower(link.Path), ".mp4") {
+		// embedding a plain http video would be blocked as mixed content
+		if link.Scheme != "https" {
+			return Video{}, "", ErrUnsupportedVideoURL
+		}
+		start := parseStart(strings.TrimPrefix(link.Fragment, "t="))
+		link.Fragment, link.RawFragment = "", ""
+		link.User = nil
+
+		video := Video{Provider: VideoMP4, Start: start, EmbedURL: link.String()}
+		if start > 0 {
+			video.EmbedURL += "#t=" + strconv.Itoa(start)
+		}
+		return video, video.EmbedURL, nil
+	}
+
+	return Video{}, "", ErrUnsupportedVideoURL
+}
+
+func parseYouTube(link *url.URL, host string) (Video, string, error) {
+	segments := strings.Split(strings.Trim(link.Path, "/"), "/")
+
+	var id string
+	switch {
+	case host == "youtu.be":
+		id = segments[0]
+	case segments[0] == "watch":
+		id = link.Query().Get("v")
+	case len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live" || segments[0] == "v"):
+		id = segments[1]
+	}
+	if !youtubeIDPattern.MatchString(id) {
+		return Video{}, "", ErrMissingVideoID
+	}
+
+	start := parseStart(link.Query().Get("t"))
+	if start == 0 {
+		start = parseStart(link.Query().Get("start"))
+	}
+
+	// the privacy-enhanced player sets no cookies until the video is played
+	video := Video{
+		Provider:     VideoYouTube,
+		VideoID:      id,
+		Start:        start,
+		EmbedURL:     "https://www.youtube-nocookie.com/embed/" + id,
+		ThumbnailURL: "https://i.ytimg.com/vi/" + id + "/hqdefault.jpg",
+	}
+	canonical := "https://www.youtube.com/watch?v=" + id
+	if start > 0 {
+		video.EmbedURL += fmt.Sprintf("?start=%d", start)
+		canonical += fmt.Sprintf("&t=%ds", start)
+	}
+	return video, canonical, nil
+}
+
+func parseVimeo(link *url.URL, host string) (Video, string, error) {
+	segments := strings.Split(strings.Trim(link.Path, "/"), "/")
+
+	var id, hash string
+	if host == "player.vimeo.com" {
+		// player.vimeo.com/video/<id>?h=<hash>
+		if len(segments) == 2 && segments[0] == "video" {
+			id = segments[1]
+		}
+		hash = link.Query().Get("h")
+	} else if vimeoIDPattern.MatchString(segments[0]) {
+		// vimeo.com/<id>, or vimeo.com/<id>/<hash> when unlisted
+		id = segments[0]
+		if len(segments) > 1 {
+			hash = segments[1]
+		}
+	} else {
+		// channels/<name>/<id>, groups/<name>/videos/<id> and showcase/<showcase>/video/<id>
+		switch {
+		case len(segments) == 3 && segments[0] == "channels":
+			id = segments[2]
+		case len(segments) == 4 && segments[0] == "groups" && segments[2] == "videos":
+			id = segments[3]
+		case len(segments) == 4 && segments[0] == "showcase" && segments[2] == "video":
+			id = segments[3]
+		}
+	}
+	if !vimeoIDPattern.MatchString(id) {
+		return Video{}, "", ErrMissingVideoID
+	}
+	if !vimeoHashPattern.MatchString(hash) {
+		hash = ""
+	}
+
+	start := parseStart(strings.TrimPrefix(link.Fragment, "t="))
+
+	video := Video{
+		Provider: VideoVimeo,
+		VideoID:  id,
+		Hash:     hash,
+		Start:    start,
+		EmbedURL: "https://player.vimeo.com/video/" + id,
+	}
+	canonical := "https://vimeo.com/" + id
+	if hash != "" {
+		video.EmbedURL += "?h=" + hash
+		canonical += "/" + hash
+	}
+	if start > 0 {
+		video.EmbedURL += fmt.Sprintf("#t=%ds", start)
+		canonical += fmt.Sprintf("#t=%ds", start)
+	}
+	return video, canonical, nil
+}
+
+// reads a start time such as "90", "90s" or "1m30s" as seconds, 0 when there is none
+func parseStart(value string) int {
+	match := startPattern.FindStringSubmatch(value)
+	if value == "" || match == nil {
+		return 0
+	}
+
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if match[i+1] != "" {
+			n, err := strconv.Atoi(match[i+1])
+			if err != nil {
+				return 0
+			}
+			seconds += n * unit
+		}
+	}
+	return seconds
+}
@@ -0,0 +1,85 @@
+package models
+
+import "testing"
+
+func TestParseVideoURL(t *testing.T) {
+	tests := []struct {
+		raw       string
+		provider  string
+		id        string
+		start     int
+		embed     string
+		canonical string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", VideoYouTube, "dQw4w9WgXcQ", 0,
+			"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"  https://youtu.be/dQw4w9WgXcQ?t=1m30s ", VideoYouTube, "dQw4w9WgXcQ", 90,
+			"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=90", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=90s"},
+		{"https://m.youtube.com/shorts/dQw4w9WgXcQ", VideoYouTube, "dQw4w9WgXcQ", 0,
+			"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=42", VideoYouTube, "dQw4w9WgXcQ", 42,
+			"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?start=42", "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42s"},
+		{"https://vimeo.com/76979871", VideoVimeo, "76979871", 0,
+			"https://player.vimeo.com/video/76979871", "https://vimeo.com/76979871"},
+		{"https://vimeo.com/76979871/8272103f6e#t=1h2m3s", VideoVimeo, "76979871", 3723,
+			"https://player.vimeo.com/video/76979871?h=8272103f6e#t=3723s", "https://vimeo.com/76979871/8272103f6e#t=3723s"},
+		{"https://player.vimeo.com/video/76979871?h=8272103f6e", VideoVimeo, "76979871", 0,
+			"https://player.vimeo.com/video/76979871?h=8272103f6e", "https://vimeo.com/76979871/8272103f6e"},
+		{"https://vimeo.com/channels/staffpicks/76979871", VideoVimeo, "76979871", 0,
+			"https://player.vimeo.com/video/76979871", "https://vimeo.com/76979871"},
+		{"https://vimeo.com/groups/cooking/videos/76979871", VideoVimeo, "76979871", 0,
+			"https://player.vimeo.com/video/76979871", "https://vimeo.com/76979871"},
+		{"https://vimeo.com/showcase/1234567/video/76979871", VideoVimeo, "76979871", 0,
+			"https://player.vimeo.com/video/76979871", "https://vimeo.com/76979871"},
+		{"https://cdn.example.com/videos/karahi.MP4#t=15", VideoMP4, "", 15,
+			"https://cdn.example.com/videos/karahi.MP4#t=15", "https://cdn.example.com/videos/karahi.MP4#t=15"},
+	}
+	for _, test := range tests {
+		video, canonical, err := ParseVideoURL(test.raw)
+		if err != nil {
+			t.Errorf("%q: %v", test.raw, err)
+			continue
+		}
+		if video.Provider != test.provider || video.VideoID != test.id || video.Start != test.start || video.EmbedURL != test.embed {
+			t.Errorf("%q: got %+v", test.raw, video)
+		}
+		if canonical != test.canonical {
+			t.Errorf("%q: canonical link is %q, want %q", test.raw, canonical, test.canonical)
+		}
+	}
+}
+
+func TestParseVideoURLErrors(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want error
+	}{
+		{"not a link", ErrMalformedVideoURL},
+		{"youtube.com/watch?v=dQw4w9WgXcQ", ErrMalformedVideoURL},
+		{"javascript://youtube.com/%0Aalert(1)", ErrUnsupportedVideoURL},
+		{"https://dailymotion.com/video/x7tgad0", ErrUnsupportedVideoURL},
+		{"http://cdn.example.com/karahi.mp4", ErrUnsupportedVideoURL},
+		{"https://www.youtube.com/watch?v=short", ErrMissingVideoID},
+		{"https://www.youtube.com/channel/UCabc", ErrMissingVideoID},
+		{"https://vimeo.com/about", ErrMissingVideoID},
+		{"https://player.vimeo.com/76979871", ErrMissingVideoID},
+		// only the known shapes end with the video
+		{"https://vimeo.com/user/76979871", ErrMissingVideoID},
+		{"https://vimeo.com/channels/76979871", ErrMissingVideoID},
+		{"https://vimeo.com/groups/cooking/76979871", ErrMissingVideoID},
+		{"https://vimeo.com/showcase/1234567/videos/76979871", ErrMissingVideoID},
+		{"https://vimeo.com/channels/staffpicks/76979871/extra", ErrMissingVideoID},
+	}
+	for _, test := range tests {
+		if _, _, err := ParseVideoURL(test.raw); err != test.want {
+			t.Errorf("%q: got %v, want %v", test.raw, err, test.want)
+		}
+	}
+}
+
+func TestParseVideoURLDropsBadHash(t *testing.T) {
+	video, canonical, err := ParseVideoURL("https://vimeo.com/76979871/not-a-hash")
+	if err != nil || video.Hash != "" || canonical != "https://vimeo.com/76979871" {
+		t.Errorf("got %+v %q %v", video, canonical, err)
+	}
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// nests the replies under the given top-level comments, replies oldest first
+func buildThreads(roots []models.Comment, replies []models.Comment) []models.CommentThread {
+	sort.Slice(replies, func(i, j int) bool { return replies[i].Created < replies[j].Created })
+
+	children := make(map[primitive.ObjectID][]models.Comment)
+	for _, reply := range replies {
+		if reply.ParentID != nil {
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+		}
+	}
+
+	var nest func(comment models.Comment) models.CommentThread
+	nest = func(comment models.Comment) models.CommentThread {
+		thread := models.CommentThread{Comment: hideDeleted(comment), Replies: []models.CommentThread{}}
+		for _, child := range children[comment.ID] {
+			thread.Replies = append(thread.Replies, nest(child))
+		}
+		return thread
+	}
+
+	threads := make([]models.CommentThread, 0, len(roots))
+	for _, root := range roots {
+		threads = append(threads, nest(root))
+	}
+	return threads
+}
+
+// deleted and hidden comments stay in their thread so replies keep their place,
+// but without the text or author
+func hideDeleted(comment models.Comment) models.Comment {
+	if comment.Deleted || comment.Hidden {
+		comment.Text = models.DeletedCommentText
+		if comment.Hidden {
+			comment.Text = models.HiddenCommentText
+		}
+		comment.Name = ""
+		comment.UserID = primitive.NilObjectID
+	}
+	return comment
+}
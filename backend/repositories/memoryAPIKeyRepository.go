@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[primitive.ObjectID]models.APIKey
+}
+
+// NewMemoryAPIKeyRepository returns an APIKeyRepository that keeps keys in memory
+func NewMemoryAPIKeyRepository() APIKeyRepository {
+	return &memoryAPIKeyRepository{keys: make(map[primitive.ObjectID]models.APIKey)}
+}
+
+func (repo *memoryAPIKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	repo.keys[key.ID] = key
+	return nil
+}
+
+func (repo *memoryAPIKeyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok {
+		return models.APIKey{}, ErrNotFound
+	}
+	return key, nil
+}
+
+func (repo *memoryAPIKeyRepository) ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range repo.keys {
+		if key.UserID == userID && key.RevokedAt == 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt > keys[j].CreatedAt })
+	return keys, nil
+}
+
+func (repo *memoryAPIKeyRepository) Touch(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if key, ok := repo.keys[id]; ok {
+		key.LastUsedAt = time.Now().Unix()
+		repo.keys[id] = key
+	}
+	return nil
+}
+
+func (repo *memoryAPIKeyRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if key, ok := repo.keys[id]; ok && key.RevokedAt == 0 {
+		key.RevokedAt = time.Now().Unix()
+		repo.keys[id] = key
+	}
+	return nil
+}
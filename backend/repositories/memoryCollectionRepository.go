@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCollectionRepository struct {
+	mu          sync.Mutex
+	collections map[primitive.ObjectID]models.Collection
+}
+
+// NewMemoryCollectionRepository returns a CollectionRepository that keeps collections in memory
+func NewMemoryCollectionRepository() CollectionRepository {
+	return &memoryCollectionRepository{collections: make(map[primitive.ObjectID]models.Collection)}
+}
+
+func (repo *memoryCollectionRepository) Create(ctx context.Context, collection models.Collection) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if collection.ID.IsZero() {
+		collection.ID = primitive.NewObjectID()
+	}
+	repo.collections[collection.ID] = collection
+	return nil
+}
+
+func (repo *memoryCollectionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Collection, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	collection, ok := repo.collections[id]
+	if !ok {
+		return models.Collection{}, ErrNotFound
+	}
+	// items are copied so that callers cannot change the stored order
+	collection.Items = append([]models.CollectionItem(nil), collection.Items...)
+	return collection, nil
+}
+
+func (repo *memoryCollectionRepository) ListByUser(ctx context.Context, userID primitive.ObjectID, publicOnly bool) ([]models.Collection, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	collections := []models.Collection{}
+	for _, collection := range repo.collections {
+		if collection.UserID == userID && (collection.Public || !publicOnly) {
+			collection.Items = append([]models.CollectionItem(nil), collection.Items...)
+			collections = append(collections, collection)
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool { return collections[i].UpdatedAt > collections[j].UpdatedAt })
+	return collections, nil
+}
+
+func (repo *memoryCollectionRepository) CountByUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var count int64
+	for _, collection := range repo.collections {
+		if collection.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *memoryCollectionRepository) UpdateDetails(ctx context.Context, id primitive.ObjectID, name, description string, public bool) error {
+	return repo.update(id, func(collection *models.Collection) bool {
+		collection.Name = name
+		collection.Description = description
+		collection.Public = public
+		return true
+	})
+}
+
+func (repo *memoryCollectionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.collections, id)
+	return nil
+}
+
+func (repo *memoryCollectionRepository) AddItem(ctx context.Context, id primitive.ObjectID, item models.CollectionItem) (bool, error) {
+	added := false
+	err := repo.update(id, func(collection *models.Collection) bool {
+		for _, existing := range collection.Items {
+			if existing.PostID == item.PostID {
+				return false
+			}
+		}
+		collection.Items = append(collection.Items, item)
+		added = true
+		return true
+	})
+	return added, err
+}
+
+func (repo *memoryCollectionRepository) SetItemNote(ctx context.Context, id, postID primitive.ObjectID, note string) (bool, error) {
+	found := false
+	err := repo.update(id, func(collection *models.Collection) bool {
+		for i := range collection.Items {
+			if collection.Items[i].PostID == postID {
+				collection.Items[i].Note = note
+				found = true
+			}
+		}
+		return found
+	})
+	return found, err
+}
+
+func (repo *memoryCollectionRepository) RemoveItem(ctx context.Context, id, postID primitive.ObjectID) (bool, error) {
+	removed := false
+	err := repo.update(id, func(collection *models.Collection) bool {
+		collection.Items, removed = withoutPost(collection.Items, postID)
+		return removed
+	})
+	return removed, err
+}
+
+func (repo *memoryCollectionRepository) SetItems(ctx context.Context, id primitive.ObjectID, items []models.CollectionItem) error {
+	return repo.update(id, func(collection *models.Collection) bool {
+		collection.Items = append([]models.CollectionItem(nil), items...)
+		return true
+	})
+}
+
+func (repo *memoryCollectionRepository) RemovePost(ctx context.Context, postID primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, collection := range repo.collections {
+		if items, removed := withoutPost(collection.Items, postID); removed {
+			collection.Items = items
+			repo.collections[id] = collection
+		}
+	}
+	return nil
+}
+
+func withoutPost(items []models.CollectionItem, postID primitive.ObjectID) ([]models.CollectionItem, bool) {
+	kept := make([]models.CollectionItem, 0, len(items))
+	for _, item := range items {
+		if item.PostID != postID {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(kept) != len(items)
+}
+
+// applies change to the stored collection, bumping updatedAt when it reports a change
+func (repo *memoryCollectionRepository) update(id primitive.ObjectID, change func(collection *models.Collection) bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	collection, ok := repo.collections[id]
+	if !ok {
+		return ErrNotFound
+	}
+	collection.Items = append([]models.CollectionItem(nil), collection.Items...)
+	if change(&collection) {
+		collection.UpdatedAt = time.Now().Unix()
+		repo.collections[id] = collection
+	}
+	return nil
+}
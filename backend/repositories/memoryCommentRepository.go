@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]models.Comment
+}
+
+// NewMemoryCommentRepository returns a CommentRepository that keeps comments in memory
+func NewMemoryCommentRepository() CommentRepository {
+	return &memoryCommentRepository{comments: make(map[primitive.ObjectID]models.Comment)}
+}
+
+func (repo *memoryCommentRepository) Create(ctx context.Context, comment models.Comment) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.comments[comment.ID] = comment
+	return nil
+}
+
+func (repo *memoryCommentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	comment, ok := repo.comments[id]
+	if !ok {
+		return models.Comment{}, ErrNotFound
+	}
+	return comment, nil
+}
+
+func (repo *memoryCommentRepository) UpdateText(ctx context.Context, id primitive.ObjectID, text string) error {
+	repo.update(id, func(comment *models.Comment) {
+		comment.Text = text
+		comment.UpdatedAt = time.Now().Unix()
+	})
+	return nil
+}
+
+func (repo *memoryCommentRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
+	repo.update(id, func(comment *models.Comment) {
+		comment.Deleted = true
+		comment.Text = ""
+		comment.UpdatedAt = time.Now().Unix()
+	})
+	return nil
+}
+
+func (repo *memoryCommentRepository) SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error {
+	repo.update(id, func(comment *models.Comment) {
+		comment.Hidden = hidden
+	})
+	return nil
+}
+
+func (repo *memoryCommentRepository) update(id primitive.ObjectID, change func(*models.Comment)) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if comment, ok := repo.comments[id]; ok {
+		change(&comment)
+		repo.comments[id] = comment
+	}
+}
+
+func (repo *memoryCommentRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, comment := range repo.comments {
+		if comment.PostID == postID {
+			delete(repo.comments, id)
+		}
+	}
+	return nil
+}
+
+func (repo *memoryCommentRepository) ListThreads(ctx context.Context, postID primitive.ObjectID, limit int, after *Cursor) (CommentPage, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var roots, replies []models.Comment
+	for _, comment := range repo.comments {
+		if comment.PostID != postID {
+			continue
+		}
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+		} else {
+			replies = append(replies, comment)
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].Created != roots[j].Created {
+			return roots[i].Created > roots[j].Created
+		}
+		return roots[i].ID.Hex() > roots[j].ID.Hex()
+	})
+
+	page := CommentPage{Total: int64(len(roots))}
+	if after != nil {
+		for len(roots) > 0 && (roots[0].Created > int64(after.Value) ||
+			(roots[0].Created == int64(after.Value) && roots[0].ID.Hex() >= after.ID.Hex())) {
+			roots = roots[1:]
+		}
+	}
+	if len(roots) > limit {
+		roots = roots[:limit]
+		last := roots[len(roots)-1]
+		page.Next = &Cursor{Value: float64(last.Created), ID: last.ID}
+	}
+
+	page.Threads = buildThreads(roots, replies)
+	return page, nil
+}
+
+func (repo *memoryCommentRepository) CountByPost(ctx context.Context, postID primitive.ObjectID) (int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	count := 0
+	for _, comment := range repo.comments {
+		if comment.PostID == postID && !comment.Deleted && !comment.Hidden {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type followKey struct {
+	followerID   primitive.ObjectID
+	kind, target string
+}
+
+type memoryFollowRepository struct {
+	mu      sync.Mutex
+	follows map[followKey]models.Follow
+}
+
+// NewMemoryFollowRepository returns a FollowRepository that keeps follows in memory
+func NewMemoryFollowRepository() FollowRepository {
+	return &memoryFollowRepository{follows: make(map[followKey]models.Follow)}
+}
+
+func (repo *memoryFollowRepository) Add(ctx context.Context, follow models.Follow) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := followKey{follow.FollowerID, follow.Kind, follow.Target}
+	if _, ok := repo.follows[key]; !ok {
+		follow.ID = primitive.NewObjectID()
+		repo.follows[key] = follow
+	}
+	return nil
+}
+
+func (repo *memoryFollowRepository) Remove(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := followKey{followerID, kind, target}
+	_, ok := repo.follows[key]
+	delete(repo.follows, key)
+	return ok, nil
+}
+
+func (repo *memoryFollowRepository) Exists(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	_, ok := repo.follows[followKey{followerID, kind, target}]
+	return ok, nil
+}
+
+func (repo *memoryFollowRepository) ListByFollower(ctx context.Context, followerID primitive.ObjectID) ([]models.Follow, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	follows := []models.Follow{}
+	for _, follow := range repo.follows {
+		if follow.FollowerID == followerID {
+			follows = append(follows, follow)
+		}
+	}
+	sort.Slice(follows, func(i, j int) bool { return follows[i].CreatedAt > follows[j].CreatedAt })
+	return follows, nil
+}
+
+func (repo *memoryFollowRepository) CountFollowers(ctx context.Context, kind, target string) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var count int64
+	for key := range repo.follows {
+		if key.kind == kind && key.target == target {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *memoryFollowRepository) CountFollowing(ctx context.Context, followerID primitive.ObjectID, kind string) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var count int64
+	for key := range repo.follows {
+		if key.followerID == followerID && key.kind == kind {
+			count++
+		}
+	}
+	return count, nil
+}
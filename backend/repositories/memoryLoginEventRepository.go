@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryLoginEventRepository struct {
+	mu     sync.Mutex
+	events []models.LoginEvent
+}
+
+// NewMemoryLoginEventRepository returns a LoginEventRepository that keeps events in memory
+func NewMemoryLoginEventRepository() LoginEventRepository {
+	return &memoryLoginEventRepository{}
+}
+
+func (repo *memoryLoginEventRepository) Create(ctx context.Context, event models.LoginEvent) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	repo.events = append(repo.events, event)
+	return nil
+}
+
+func (repo *memoryLoginEventRepository) ListByUser(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.LoginEvent, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	events := []models.LoginEvent{}
+	for _, event := range repo.events {
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].CreatedAt > events[j].CreatedAt })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
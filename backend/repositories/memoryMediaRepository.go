@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMediaRepository struct {
+	mu    sync.Mutex
+	media map[primitive.ObjectID]models.Media
+}
+
+// NewMemoryMediaRepository returns a MediaRepository that keeps media records in memory
+func NewMemoryMediaRepository() MediaRepository {
+	return &memoryMediaRepository{media: make(map[primitive.ObjectID]models.Media)}
+}
+
+func (repo *memoryMediaRepository) Create(ctx context.Context, media models.Media) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if media.ID.IsZero() {
+		media.ID = primitive.NewObjectID()
+	}
+	repo.media[media.ID] = media
+	return nil
+}
+
+func (repo *memoryMediaRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Media, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	media, ok := repo.media[id]
+	if !ok {
+		return models.Media{}, ErrNotFound
+	}
+	return media, nil
+}
+
+func (repo *memoryMediaRepository) ListPending(ctx context.Context) ([]models.Media, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var pending []models.Media
+	for _, media := range repo.media {
+		if media.Status == models.MediaPending {
+			pending = append(pending, media)
+		}
+	}
+	return pending, nil
+}
+
+func (repo *memoryMediaRepository) SetImageDetails(ctx context.Context, id primitive.ObjectID, status string, details models.ImageDetails) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	media, ok := repo.media[id]
+	if !ok {
+		return ErrNotFound
+	}
+	media.Status = status
+	media.ImageDetails = details
+	repo.media[id] = media
+	return nil
+}
@@ -0,0 +1,378 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPostRepository struct {
+	mu    sync.RWMutex
+	posts map[primitive.ObjectID]models.Post
+	users UserRepository
+}
+
+// NewMemoryPostRepository returns a PostRepository that keeps posts in memory,
+// for tests and running the API without a database. Authors are looked up in users.
+func NewMemoryPostRepository(users UserRepository) PostRepository {
+	return &memoryPostRepository{posts: make(map[primitive.ObjectID]models.Post), users: users}
+}
+
+func (repo *memoryPostRepository) Create(ctx context.Context, post models.Post) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.posts[post.ID] = post
+	return nil
+}
+
+func (repo *memoryPostRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	post, ok := repo.posts[id]
+	if !ok {
+		return models.Post{}, ErrNotFound
+	}
+	return post, nil
+}
+
+func (repo *memoryPostRepository) List(ctx context.Context, query PostQuery) (PostPage, error) {
+	now := query.now()
+	posts := repo.filter(func(post models.Post) bool { return matchesQuery(post, query) })
+
+	values := make(map[primitive.ObjectID]float64, len(posts))
+	for _, post := range posts {
+		values[post.ID] = sortValue(post, query.Sort, now)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		if values[posts[i].ID] != values[posts[j].ID] {
+			return values[posts[i].ID] > values[posts[j].ID]
+		}
+		return posts[i].ID.Hex() > posts[j].ID.Hex()
+	})
+
+	page := PostPage{Posts: []models.PostSummary{}, Total: int64(len(posts))}
+	for _, post := range posts {
+		if query.After != nil {
+			value := values[post.ID]
+			if value > query.After.Value || (value == query.After.Value && post.ID.Hex() >= query.After.ID.Hex()) {
+				continue
+			}
+		}
+
+		if len(page.Posts) == query.Limit {
+			last := page.Posts[len(page.Posts)-1]
+			page.Next = &Cursor{Value: values[last.ID], ID: last.ID}
+			if query.Sort == SortTrending {
+				page.Next.Now = now
+			}
+			break
+		}
+		page.Posts = append(page.Posts, repo.summarize(ctx, post))
+	}
+
+	return page, nil
+}
+
+func (repo *memoryPostRepository) Summaries(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.PostSummary, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	summaries := make(map[primitive.ObjectID]models.PostSummary, len(ids))
+	for _, post := range repo.filter(func(post models.Post) bool { return wanted[post.ID] && !post.Hidden }) {
+		summaries[post.ID] = repo.summarize(ctx, post)
+	}
+	return summaries, nil
+}
+
+func matchesQuery(post models.Post, query PostQuery) bool {
+	if post.Hidden {
+		return false
+	}
+	if query.Country != "" && post.Country != query.Country {
+		return false
+	}
+	if !query.AuthorID.IsZero() && post.UserID != query.AuthorID {
+		return false
+	}
+	if !query.ExcludeID.IsZero() && post.ID == query.ExcludeID {
+		return false
+	}
+	if len(query.FollowedAuthors) > 0 || len(query.FollowedCountries) > 0 {
+		if !followed(post, query) {
+			return false
+		}
+	}
+	if query.From != 0 && post.CreatedAt < query.From {
+		return false
+	}
+	if query.To != 0 && post.CreatedAt >= query.To {
+		return false
+	}
+	if query.Ingredient != "" {
+		for _, ingredient := range post.Recipe.Ingredients {
+			if strings.Contains(strings.ToLower(ingredient.Name), strings.ToLower(query.Ingredient)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func followed(post models.Post, query PostQuery) bool {
+	for _, authorID := range query.FollowedAuthors {
+		if post.UserID == authorID {
+			return true
+		}
+	}
+	for _, country := range query.FollowedCountries {
+		if post.Country == country {
+			return true
+		}
+	}
+	return false
+}
+
+func sortValue(post models.Post, sort string, now int64) float64 {
+	switch sort {
+	case SortMostLiked:
+		return float64(post.Likes)
+	case SortMostCommented:
+		return float64(post.CommentCount)
+	case SortTrending:
+		return TrendingScore(post, now)
+	default:
+		return float64(post.CreatedAt)
+	}
+}
+
+// the in-memory equivalent of the $lookup done by summaryStages
+func (repo *memoryPostRepository) summarize(ctx context.Context, post models.Post) models.PostSummary {
+	summary := models.PostSummary{
+		ID:           post.ID,
+		UserID:       post.UserID,
+		UserName:     models.DeletedUserName,
+		Title:        post.Title,
+		Description:  post.Description,
+		VideoURL:     post.VideoURL,
+		Video:        post.Video,
+		Media:        post.Media,
+		Country:      post.Country,
+		Ingredients:  []string{},
+		Likes:        post.Likes,
+		Dislikes:     post.Dislikes,
+		Reactions:    post.Reactions,
+		CommentCount: post.CommentCount,
+		CreatedAt:    post.CreatedAt,
+	}
+
+	if summary.Media == nil {
+		summary.Media = []models.PostMedia{}
+	}
+
+	for _, ingredient := range post.Recipe.Ingredients {
+		summary.Ingredients = append(summary.Ingredients, ingredient.Name)
+	}
+
+	if author, err := repo.users.FindByID(ctx, post.UserID); err == nil {
+		summary.UserName = author.Name
+		summary.UserAvatar = author.Avatar
+	}
+
+	return summary
+}
+
+// returns the matching posts, newest first so results are stable between calls
+func (repo *memoryPostRepository) filter(match func(models.Post) bool) []models.Post {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var posts []models.Post
+	for _, post := range repo.posts {
+		if match(post) {
+			posts = append(posts, post)
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool { return posts[i].CreatedAt > posts[j].CreatedAt })
+	return posts
+}
+
+// approximates the weights of the Mongo text index created in config.ConnectDB
+func (repo *memoryPostRepository) Search(ctx context.Context, query string, limit int, after *Cursor) (SearchPage, error) {
+	terms := utils.SearchTerms(query)
+
+	var hits []SearchHit
+	total := int64(0)
+	for _, post := range repo.filter(func(post models.Post) bool { return !post.Hidden }) {
+		score := float64(10*utils.CountMatches(post.Title, terms) +
+			6*utils.CountMatches(post.Country, terms) +
+			4*utils.CountMatches(post.Recipe.SearchText(), terms) +
+			3*utils.CountMatches(post.AuthorName, terms) +
+			2*utils.CountMatches(post.Description, terms))
+		if score == 0 {
+			continue
+		}
+		total++
+		if after != nil && !(score < after.Value || (score == after.Value && post.ID.Hex() < after.ID.Hex())) {
+			continue
+		}
+		hits = append(hits, SearchHit{Post: repo.summarize(ctx, post), Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Post.ID.Hex() > hits[j].Post.ID.Hex()
+	})
+	if len(hits) > limit+1 {
+		hits = hits[:limit+1]
+	}
+	return searchPage(hits, total, limit), nil
+}
+
+func (repo *memoryPostRepository) Update(ctx context.Context, update models.Post) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	post, ok := repo.posts[update.ID]
+	if !ok || post.UserID != update.UserID {
+		return nil
+	}
+
+	post.Title = update.Title
+	post.Description = update.Description
+	post.VideoURL = update.VideoURL
+	post.Video = update.Video
+	post.Recipe = update.Recipe
+	post.Media = update.Media
+	post.Country = update.Country
+	post.UpdatedAt = time.Now().Unix()
+	repo.posts[post.ID] = post
+	return nil
+}
+
+func (repo *memoryPostRepository) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if post, ok := repo.posts[id]; ok && post.UserID == userID {
+		delete(repo.posts, id)
+	}
+	return nil
+}
+
+func (repo *memoryPostRepository) DeleteAny(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.posts, id)
+	return nil
+}
+
+func (repo *memoryPostRepository) SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	post, ok := repo.posts[id]
+	if !ok {
+		return ErrNotFound
+	}
+	post.Hidden = hidden
+	repo.posts[id] = post
+	return nil
+}
+
+func (repo *memoryPostRepository) SetCommentCount(ctx context.Context, id primitive.ObjectID, count int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if post, ok := repo.posts[id]; ok {
+		post.CommentCount = count
+		repo.posts[id] = post
+	}
+	return nil
+}
+
+func (repo *memoryPostRepository) SetAuthorName(ctx context.Context, userID primitive.ObjectID, name string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, post := range repo.posts {
+		if post.UserID == userID {
+			post.AuthorName = name
+			repo.posts[id] = post
+		}
+	}
+	return nil
+}
+
+func (repo *memoryPostRepository) SetMediaDetails(ctx context.Context, mediaID primitive.ObjectID, details models.ImageDetails) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, post := range repo.posts {
+		// copied, as posts handed out earlier share the old slice
+		media := append([]models.PostMedia(nil), post.Media...)
+		changed := false
+		for i := range media {
+			if media[i].ID == mediaID {
+				media[i].ImageDetails = details
+				changed = true
+			}
+		}
+		if changed {
+			post.Media = media
+			repo.posts[id] = post
+		}
+	}
+	return nil
+}
+
+func (repo *memoryPostRepository) AuthorStats(ctx context.Context, userID primitive.ObjectID) (int64, int64, error) {
+	var posts, likes int64
+	for _, post := range repo.filter(func(post models.Post) bool { return post.UserID == userID && !post.Hidden }) {
+		posts++
+		likes += int64(post.Likes)
+	}
+	return posts, likes, nil
+}
+
+func (repo *memoryPostRepository) AddReactionCount(ctx context.Context, id primitive.ObjectID, kind string, delta int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	post, ok := repo.posts[id]
+	if !ok {
+		return nil
+	}
+
+	// the map is copied, as the post handed out by FindByID shares it
+	reactions := make(map[string]int, len(post.Reactions)+1)
+	for k, count := range post.Reactions {
+		reactions[k] = count
+	}
+	reactions[kind] += delta
+	post.Reactions = reactions
+
+	switch kind {
+	case models.ReactionLike:
+		post.Likes += delta
+	case models.ReactionDislike:
+		post.Dislikes += delta
+	}
+	repo.posts[id] = post
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type reactionKey struct {
+	userID, postID primitive.ObjectID
+	kind           string
+}
+
+type memoryReactionRepository struct {
+	mu        sync.Mutex
+	reactions map[reactionKey]models.Reaction
+}
+
+// NewMemoryReactionRepository returns a ReactionRepository that keeps reactions in memory
+func NewMemoryReactionRepository() ReactionRepository {
+	return &memoryReactionRepository{reactions: make(map[reactionKey]models.Reaction)}
+}
+
+func (repo *memoryReactionRepository) Add(ctx context.Context, reaction models.Reaction) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := reactionKey{reaction.UserID, reaction.PostID, reaction.Kind}
+	if _, ok := repo.reactions[key]; ok {
+		return false, nil
+	}
+	reaction.ID = primitive.NewObjectID()
+	repo.reactions[key] = reaction
+	return true, nil
+}
+
+func (repo *memoryReactionRepository) Remove(ctx context.Context, userID, postID primitive.ObjectID, kind string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key := reactionKey{userID, postID, kind}
+	_, ok := repo.reactions[key]
+	delete(repo.reactions, key)
+	return ok, nil
+}
+
+func (repo *memoryReactionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for key := range repo.reactions {
+		if key.postID == postID {
+			delete(repo.reactions, key)
+		}
+	}
+	return nil
+}
+
+func (repo *memoryReactionRepository) KindsByUser(ctx context.Context, userID, postID primitive.ObjectID) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	kinds := []string{}
+	for key := range repo.reactions {
+		if key.userID == userID && key.postID == postID {
+			kinds = append(kinds, key.kind)
+		}
+	}
+	return kinds, nil
+}
+
+func (repo *memoryReactionRepository) KindsByUserForPosts(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	wanted := make(map[primitive.ObjectID]bool, len(postIDs))
+	for _, id := range postIDs {
+		wanted[id] = true
+	}
+
+	kinds := make(map[primitive.ObjectID][]string)
+	for key := range repo.reactions {
+		if key.userID == userID && wanted[key.postID] {
+			kinds[key.postID] = append(kinds[key.postID], key.kind)
+		}
+	}
+	return kinds, nil
+}
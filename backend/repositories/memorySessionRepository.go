@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+// NewMemorySessionRepository returns a SessionRepository that keeps sessions in memory
+func NewMemorySessionRepository() SessionRepository {
+	return &memorySessionRepository{sessions: make(map[primitive.ObjectID]models.Session)}
+}
+
+func (repo *memorySessionRepository) Create(ctx context.Context, session models.Session) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	repo.sessions[session.ID] = session
+	return nil
+}
+
+func (repo *memorySessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Session, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	session, ok := repo.sessions[id]
+	if !ok {
+		return models.Session{}, ErrNotFound
+	}
+	return session, nil
+}
+
+func (repo *memorySessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt int64) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	session, ok := repo.sessions[id]
+	if !ok || session.RefreshHash != oldHash || session.RevokedAt != 0 {
+		return false, nil
+	}
+	session.PreviousHash = oldHash
+	session.RefreshHash = newHash
+	session.LastUsedAt = time.Now().Unix()
+	session.ExpiresAt = expiresAt
+	repo.sessions[id] = session
+	return true, nil
+}
+
+func (repo *memorySessionRepository) ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().Unix()
+	sessions := []models.Session{}
+	for _, session := range repo.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt > sessions[j].LastUsedAt })
+	return sessions, nil
+}
+
+func (repo *memorySessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if session, ok := repo.sessions[id]; ok && session.RevokedAt == 0 {
+		session.RevokedAt = time.Now().Unix()
+		repo.sessions[id] = session
+	}
+	return nil
+}
+
+func (repo *memorySessionRepository) RevokeAllByUser(ctx context.Context, userID primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().Unix()
+	for id, session := range repo.sessions {
+		if session.UserID == userID && session.RevokedAt == 0 {
+			session.RevokedAt = now
+			repo.sessions[id] = session
+		}
+	}
+	return nil
+}
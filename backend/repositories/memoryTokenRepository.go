@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]models.UserToken
+}
+
+// NewMemoryTokenRepository returns a TokenRepository that keeps tokens in memory
+func NewMemoryTokenRepository() TokenRepository {
+	return &memoryTokenRepository{tokens: make(map[primitive.ObjectID]models.UserToken)}
+}
+
+func (repo *memoryTokenRepository) Create(ctx context.Context, token models.UserToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	repo.tokens[token.ID] = token
+	return nil
+}
+
+func (repo *memoryTokenRepository) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().Unix()
+	for id, token := range repo.tokens {
+		if token.Purpose == purpose && token.Hash == hash && token.UsedAt == 0 && token.ExpiresAt > now {
+			token.UsedAt = now
+			repo.tokens[id] = token
+			return token, nil
+		}
+	}
+	return models.UserToken{}, ErrNotFound
+}
+
+func (repo *memoryTokenRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, token := range repo.tokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(repo.tokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+// NewMemoryUserRepository returns a UserRepository that keeps users in memory,
+// for tests and running the API without a database
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[primitive.ObjectID]models.User)}
+}
+
+func (repo *memoryUserRepository) Create(ctx context.Context, user models.User) (primitive.ObjectID, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if user.Handle != "" && repo.handleTaken(user.Handle, user.ID) {
+		return primitive.NilObjectID, ErrHandleTaken
+	}
+	repo.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (repo *memoryUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (repo *memoryUserRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	users := []models.User{}
+	for _, id := range ids {
+		if user, ok := repo.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (repo *memoryUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, user := range repo.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (repo *memoryUserRepository) FindByHandle(ctx context.Context, handle string) (models.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, user := range repo.users {
+		if user.Handle == handle {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (repo *memoryUserRepository) UpdateProfile(ctx context.Context, id primitive.ObjectID, profile models.ProfileUpdate) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if repo.handleTaken(profile.Handle, id) {
+		return ErrHandleTaken
+	}
+
+	user.Name = profile.Name
+	user.Handle = profile.Handle
+	user.Bio = profile.Bio
+	user.Avatar = profile.Avatar
+	user.Country = profile.Country
+	repo.users[id] = user
+	return nil
+}
+
+// the in-memory equivalent of the unique index on handle; the caller holds the lock
+func (repo *memoryUserRepository) handleTaken(handle string, id primitive.ObjectID) bool {
+	for _, user := range repo.users {
+		if user.Handle == handle && user.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (repo *memoryUserRepository) List(ctx context.Context, skip, limit int) ([]models.User, int64, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	users := make([]models.User, 0, len(repo.users))
+	for _, user := range repo.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID.Hex() < users[j].ID.Hex() })
+
+	total := int64(len(users))
+	if skip >= len(users) {
+		return []models.User{}, total, nil
+	}
+	users = users[skip:]
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, total, nil
+}
+
+func (repo *memoryUserRepository) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	return repo.update(id, func(user *models.User) { user.Role = role })
+}
+
+func (repo *memoryUserRepository) SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) error {
+	return repo.update(id, func(user *models.User) { user.Password = hashedPassword })
+}
+
+func (repo *memoryUserRepository) SetEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	return repo.update(id, func(user *models.User) { user.EmailVerified = true })
+}
+
+func (repo *memoryUserRepository) SetTwoFactor(ctx context.Context, id primitive.ObjectID, twoFactor models.TwoFactor) error {
+	return repo.update(id, func(user *models.User) { user.TwoFactor = twoFactor })
+}
+
+func (repo *memoryUserRepository) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	used := false
+	err := repo.update(id, func(user *models.User) {
+		if user.TwoFactor.LastStep < step {
+			user.TwoFactor.LastStep = step
+			used = true
+		}
+	})
+	return used, err
+}
+
+func (repo *memoryUserRepository) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	used := false
+	err := repo.update(id, func(user *models.User) {
+		for i, code := range user.TwoFactor.RecoveryCodes {
+			if code == hash {
+				user.TwoFactor.RecoveryCodes = append(user.TwoFactor.RecoveryCodes[:i:i], user.TwoFactor.RecoveryCodes[i+1:]...)
+				used = true
+				return
+			}
+		}
+	})
+	return used, err
+}
+
+func (repo *memoryUserRepository) RecordFailedLogin(ctx context.Context, id primitive.ObjectID) (int, error) {
+	failures := 0
+	err := repo.update(id, func(user *models.User) {
+		user.FailedLogins++
+		failures = user.FailedLogins
+	})
+	return failures, err
+}
+
+func (repo *memoryUserRepository) LockUntil(ctx context.Context, id primitive.ObjectID, until int64) error {
+	return repo.update(id, func(user *models.User) {
+		user.LockedUntil = until
+		user.FailedLogins = 0
+	})
+}
+
+func (repo *memoryUserRepository) ResetFailedLogins(ctx context.Context, id primitive.ObjectID) error {
+	return repo.update(id, func(user *models.User) {
+		user.LockedUntil = 0
+		user.FailedLogins = 0
+	})
+}
+
+func (repo *memoryUserRepository) update(id primitive.ObjectID, change func(user *models.User)) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	change(&user)
+	repo.users[id] = user
+	return nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAPIKeyRepository struct {
+	keys *mongo.Collection
+}
+
+// NewMongoAPIKeyRepository returns an APIKeyRepository backed by the "apiKeys" collection
+func NewMongoAPIKeyRepository(db *mongo.Database) APIKeyRepository {
+	return &mongoAPIKeyRepository{keys: db.Collection("apiKeys")}
+}
+
+func (repo *mongoAPIKeyRepository) Create(ctx context.Context, key models.APIKey) error {
+	_, err := repo.keys.InsertOne(ctx, key)
+	return err
+}
+
+func (repo *mongoAPIKeyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.APIKey, error) {
+	var key models.APIKey
+	err := repo.keys.FindOne(ctx, bson.M{"_id": id}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return key, ErrNotFound
+	}
+	return key, err
+}
+
+func (repo *mongoAPIKeyRepository) ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	filter := bson.M{"userID": userID, "revokedAt": bson.M{"$exists": false}}
+	cursor, err := repo.keys.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (repo *mongoAPIKeyRepository) Touch(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.keys.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"lastUsedAt": time.Now().Unix()}})
+	return err
+}
+
+func (repo *mongoAPIKeyRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.keys.UpdateOne(ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now().Unix()}},
+	)
+	return err
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoCollectionRepository struct {
+	collections *mongo.Collection
+}
+
+// NewMongoCollectionRepository returns a CollectionRepository backed by the "collections" collection
+func NewMongoCollectionRepository(db *mongo.Database) CollectionRepository {
+	return &mongoCollectionRepository{collections: db.Collection("collections")}
+}
+
+func (repo *mongoCollectionRepository) Create(ctx context.Context, collection models.Collection) error {
+	if collection.Items == nil {
+		collection.Items = []models.CollectionItem{}
+	}
+	_, err := repo.collections.InsertOne(ctx, collection)
+	return err
+}
+
+func (repo *mongoCollectionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Collection, error) {
+	var collection models.Collection
+	err := repo.collections.FindOne(ctx, bson.M{"_id": id}).Decode(&collection)
+	if err == mongo.ErrNoDocuments {
+		return collection, ErrNotFound
+	}
+	return collection, err
+}
+
+func (repo *mongoCollectionRepository) ListByUser(ctx context.Context, userID primitive.ObjectID, publicOnly bool) ([]models.Collection, error) {
+	filter := bson.M{"userID": userID}
+	if publicOnly {
+		filter["public"] = true
+	}
+
+	cursor, err := repo.collections.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	collections := []models.Collection{}
+	if err := cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func (repo *mongoCollectionRepository) CountByUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return repo.collections.CountDocuments(ctx, bson.M{"userID": userID})
+}
+
+func (repo *mongoCollectionRepository) UpdateDetails(ctx context.Context, id primitive.ObjectID, name, description string, public bool) error {
+	result, err := repo.collections.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"name":        name,
+		"description": description,
+		"public":      public,
+		"updatedAt":   time.Now().Unix(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (repo *mongoCollectionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.collections.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// the post is only pushed when it is not in the collection yet
+func (repo *mongoCollectionRepository) AddItem(ctx context.Context, id primitive.ObjectID, item models.CollectionItem) (bool, error) {
+	result, err := repo.collections.UpdateOne(ctx,
+		bson.M{"_id": id, "items.postID": bson.M{"$ne": item.PostID}},
+		bson.M{
+			"$push": bson.M{"items": item},
+			"$set":  bson.M{"updatedAt": time.Now().Unix()},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *mongoCollectionRepository) SetItemNote(ctx context.Context, id, postID primitive.ObjectID, note string) (bool, error) {
+	result, err := repo.collections.UpdateOne(ctx,
+		bson.M{"_id": id, "items.postID": postID},
+		bson.M{"$set": bson.M{"items.$.note": note, "updatedAt": time.Now().Unix()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (repo *mongoCollectionRepository) RemoveItem(ctx context.Context, id, postID primitive.ObjectID) (bool, error) {
+	result, err := repo.collections.UpdateOne(ctx,
+		bson.M{"_id": id, "items.postID": postID},
+		bson.M{
+			"$pull": bson.M{"items": bson.M{"postID": postID}},
+			"$set":  bson.M{"updatedAt": time.Now().Unix()},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *mongoCollectionRepository) SetItems(ctx context.Context, id primitive.ObjectID, items []models.CollectionItem) error {
+	_, err := repo.collections.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"items":     items,
+		"updatedAt": time.Now().Unix(),
+	}})
+	return err
+}
+
+func (repo *mongoCollectionRepository) RemovePost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := repo.collections.UpdateMany(ctx,
+		bson.M{"items.postID": postID},
+		bson.M{"$pull": bson.M{"items": bson.M{"postID": postID}}},
+	)
+	return err
+}
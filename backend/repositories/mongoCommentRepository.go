@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoCommentRepository struct {
+	comments *mongo.Collection
+}
+
+// NewMongoCommentRepository returns a CommentRepository backed by the "comments" collection
+func NewMongoCommentRepository(db *mongo.Database) CommentRepository {
+	return &mongoCommentRepository{comments: db.Collection("comments")}
+}
+
+func (repo *mongoCommentRepository) Create(ctx context.Context, comment models.Comment) error {
+	_, err := repo.comments.InsertOne(ctx, comment)
+	return err
+}
+
+func (repo *mongoCommentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error) {
+	var comment models.Comment
+	err := repo.comments.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return comment, ErrNotFound
+	}
+	return comment, err
+}
+
+func (repo *mongoCommentRepository) UpdateText(ctx context.Context, id primitive.ObjectID, text string) error {
+	_, err := repo.comments.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"text": text, "updatedAt": time.Now().Unix()},
+	})
+	return err
+}
+
+func (repo *mongoCommentRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.comments.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"deleted": true, "updatedAt": time.Now().Unix()},
+		"$unset": bson.M{"text": ""},
+	})
+	return err
+}
+
+func (repo *mongoCommentRepository) SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error {
+	_, err := repo.comments.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"hidden": hidden}})
+	return err
+}
+
+func (repo *mongoCommentRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := repo.comments.DeleteMany(ctx, bson.M{"postID": postID})
+	return err
+}
+
+func (repo *mongoCommentRepository) ListThreads(ctx context.Context, postID primitive.ObjectID, limit int, after *Cursor) (CommentPage, error) {
+	filter := bson.M{"postID": postID, "parentID": bson.M{"$exists": false}}
+
+	total, err := repo.comments.CountDocuments(ctx, filter)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created": bson.M{"$lt": int64(after.Value)}},
+			bson.M{"created": int64(after.Value), "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	// one extra comment is fetched to find out whether there is a next page
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := repo.comments.Find(ctx, filter, findOptions)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	var roots []models.Comment
+	if err := cursor.All(ctx, &roots); err != nil {
+		return CommentPage{}, err
+	}
+
+	page := CommentPage{Total: total}
+	if len(roots) > limit {
+		roots = roots[:limit]
+		last := roots[len(roots)-1]
+		page.Next = &Cursor{Value: float64(last.Created), ID: last.ID}
+	}
+
+	rootIDs := make([]primitive.ObjectID, 0, len(roots))
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.ID)
+	}
+
+	cursor, err = repo.comments.Find(ctx, bson.M{
+		"rootID":   bson.M{"$in": rootIDs},
+		"parentID": bson.M{"$exists": true},
+	})
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	var replies []models.Comment
+	if err := cursor.All(ctx, &replies); err != nil {
+		return CommentPage{}, err
+	}
+
+	page.Threads = buildThreads(roots, replies)
+	return page, nil
+}
+
+func (repo *mongoCommentRepository) CountByPost(ctx context.Context, postID primitive.ObjectID) (int, error) {
+	count, err := repo.comments.CountDocuments(ctx, bson.M{"postID": postID, "deleted": bson.M{"$ne": true}, "hidden": bson.M{"$ne": true}})
+	return int(count), err
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFollowRepository struct {
+	follows *mongo.Collection
+}
+
+// NewMongoFollowRepository returns a FollowRepository backed by the "follows" collection
+func NewMongoFollowRepository(db *mongo.Database) FollowRepository {
+	return &mongoFollowRepository{follows: db.Collection("follows")}
+}
+
+func (repo *mongoFollowRepository) Add(ctx context.Context, follow models.Follow) error {
+	filter := bson.M{"followerID": follow.FollowerID, "kind": follow.Kind, "target": follow.Target}
+	update := bson.M{"$setOnInsert": bson.M{"createdAt": follow.CreatedAt}}
+
+	_, err := repo.follows.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	// as with reactions, a racing upsert means the follow exists either way
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (repo *mongoFollowRepository) Remove(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error) {
+	result, err := repo.follows.DeleteOne(ctx, bson.M{"followerID": followerID, "kind": kind, "target": target})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (repo *mongoFollowRepository) Exists(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error) {
+	count, err := repo.follows.CountDocuments(ctx, bson.M{"followerID": followerID, "kind": kind, "target": target})
+	return count > 0, err
+}
+
+func (repo *mongoFollowRepository) ListByFollower(ctx context.Context, followerID primitive.ObjectID) ([]models.Follow, error) {
+	cursor, err := repo.follows.Find(ctx, bson.M{"followerID": followerID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	follows := []models.Follow{}
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+func (repo *mongoFollowRepository) CountFollowers(ctx context.Context, kind, target string) (int64, error) {
+	return repo.follows.CountDocuments(ctx, bson.M{"kind": kind, "target": target})
+}
+
+func (repo *mongoFollowRepository) CountFollowing(ctx context.Context, followerID primitive.ObjectID, kind string) (int64, error) {
+	return repo.follows.CountDocuments(ctx, bson.M{"followerID": followerID, "kind": kind})
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLoginEventRepository struct {
+	events *mongo.Collection
+}
+
+// NewMongoLoginEventRepository returns a LoginEventRepository backed by the "loginEvents" collection
+func NewMongoLoginEventRepository(db *mongo.Database) LoginEventRepository {
+	return &mongoLoginEventRepository{events: db.Collection("loginEvents")}
+}
+
+func (repo *mongoLoginEventRepository) Create(ctx context.Context, event models.LoginEvent) error {
+	_, err := repo.events.InsertOne(ctx, event)
+	return err
+}
+
+func (repo *mongoLoginEventRepository) ListByUser(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.LoginEvent, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := repo.events.Find(ctx, bson.M{"userID": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []models.LoginEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
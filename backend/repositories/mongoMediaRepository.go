@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoMediaRepository struct {
+	media *mongo.Collection
+}
+
+// NewMongoMediaRepository returns a MediaRepository backed by the "media" collection
+func NewMongoMediaRepository(db *mongo.Database) MediaRepository {
+	return &mongoMediaRepository{media: db.Collection("media")}
+}
+
+func (repo *mongoMediaRepository) Create(ctx context.Context, media models.Media) error {
+	_, err := repo.media.InsertOne(ctx, media)
+	return err
+}
+
+func (repo *mongoMediaRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Media, error) {
+	var media models.Media
+	err := repo.media.FindOne(ctx, bson.M{"_id": id}).Decode(&media)
+	if err == mongo.ErrNoDocuments {
+		return media, ErrNotFound
+	}
+	return media, err
+}
+
+func (repo *mongoMediaRepository) ListPending(ctx context.Context) ([]models.Media, error) {
+	cursor, err := repo.media.Find(ctx, bson.M{"status": models.MediaPending})
+	if err != nil {
+		return nil, err
+	}
+
+	var media []models.Media
+	if err := cursor.All(ctx, &media); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func (repo *mongoMediaRepository) SetImageDetails(ctx context.Context, id primitive.ObjectID, status string, details models.ImageDetails) error {
+	_, err := repo.media.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":   status,
+		"width":    details.Width,
+		"height":   details.Height,
+		"blurhash": details.Blurhash,
+		"color":    details.Color,
+		"variants": details.Variants,
+	}})
+	return err
+}
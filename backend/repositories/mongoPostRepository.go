@@ -0,0 +1,365 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPostRepository struct {
+	posts *mongo.Collection
+}
+
+// NewMongoPostRepository returns a PostRepository backed by the "posts" collection
+func NewMongoPostRepository(db *mongo.Database) PostRepository {
+	return &mongoPostRepository{posts: db.Collection("posts")}
+}
+
+func (repo *mongoPostRepository) Create(ctx context.Context, post models.Post) error {
+	_, err := repo.posts.InsertOne(ctx, post)
+	return err
+}
+
+func (repo *mongoPostRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	var post models.Post
+	err := repo.posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post)
+	if err == mongo.ErrNoDocuments {
+		return post, ErrNotFound
+	}
+	return post, err
+}
+
+func (repo *mongoPostRepository) List(ctx context.Context, query PostQuery) (PostPage, error) {
+	filter := listFilter(query)
+
+	total, err := repo.posts.CountDocuments(ctx, filter)
+	if err != nil {
+		return PostPage{}, err
+	}
+
+	now := query.now()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"sortValue": sortExpression(query.Sort, now)}}},
+	}
+
+	if query.After != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"sortValue": bson.M{"$lt": query.After.Value}},
+			bson.M{"sortValue": query.After.Value, "_id": bson.M{"$lt": query.After.ID}},
+		}}}})
+	}
+
+	// one extra post is fetched to find out whether there is a next page
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "sortValue", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: int64(query.Limit + 1)}},
+	)
+	pipeline = append(pipeline, summaryStages("sortValue")...)
+
+	cursor, err := repo.posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		return PostPage{}, err
+	}
+
+	var results []struct {
+		models.PostSummary `bson:",inline"`
+		SortValue          float64 `bson:"sortValue"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return PostPage{}, err
+	}
+
+	page := PostPage{Posts: []models.PostSummary{}, Total: total}
+	for i, result := range results {
+		if i == query.Limit {
+			last := results[i-1]
+			page.Next = &Cursor{Value: last.SortValue, ID: last.ID}
+			if query.Sort == SortTrending {
+				page.Next.Now = now
+			}
+			break
+		}
+		page.Posts = append(page.Posts, result.PostSummary)
+	}
+
+	return page, nil
+}
+
+func (repo *mongoPostRepository) Summaries(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.PostSummary, error) {
+	summaries := make(map[primitive.ObjectID]models.PostSummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	pipeline := append(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$in": ids}, "hidden": bson.M{"$ne": true}}}},
+	}, summaryStages()...)
+
+	cursor, err := repo.posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.PostSummary
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	for _, summary := range results {
+		summaries[summary.ID] = summary
+	}
+	return summaries, nil
+}
+
+// looks up each post's author and projects the post into a models.PostSummary,
+// keeping the extra fields named in keep (such as a sort value)
+func summaryStages(keep ...string) mongo.Pipeline {
+	project := bson.M{
+		"userID":       1,
+		"title":        1,
+		"description":  1,
+		"video_url":    1,
+		"video":        1,
+		"media":        bson.M{"$ifNull": bson.A{"$media", bson.A{}}},
+		"country":      1,
+		"likes":        1,
+		"dislikes":     1,
+		"reactions":    1,
+		"createdAt":    1,
+		"ingredients":  bson.M{"$ifNull": bson.A{"$recipe.ingredients.name", bson.A{}}},
+		"commentCount": 1,
+		"userName":     bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$author.name", 0}}, models.DeletedUserName}},
+		"userAvatar":   bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$author.avatar", 0}}, ""}},
+	}
+	for _, field := range keep {
+		project[field] = 1
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "userID",
+			"foreignField": "_id",
+			"as":           "author",
+		}}},
+		{{Key: "$project", Value: project}},
+	}
+}
+
+func listFilter(query PostQuery) bson.M {
+	filter := bson.M{"hidden": bson.M{"$ne": true}}
+
+	if query.Country != "" {
+		filter["country"] = query.Country
+	}
+	if !query.AuthorID.IsZero() {
+		filter["userID"] = query.AuthorID
+	}
+	if query.Ingredient != "" {
+		filter["recipe.ingredients.name"] = primitive.Regex{Pattern: regexp.QuoteMeta(query.Ingredient), Options: "i"}
+	}
+	if !query.ExcludeID.IsZero() {
+		filter["_id"] = bson.M{"$ne": query.ExcludeID}
+	}
+
+	var followed bson.A
+	if len(query.FollowedAuthors) > 0 {
+		followed = append(followed, bson.M{"userID": bson.M{"$in": query.FollowedAuthors}})
+	}
+	if len(query.FollowedCountries) > 0 {
+		followed = append(followed, bson.M{"country": bson.M{"$in": query.FollowedCountries}})
+	}
+	if len(followed) > 0 {
+		filter["$or"] = followed
+	}
+
+	createdAt := bson.M{}
+	if query.From != 0 {
+		createdAt["$gte"] = query.From
+	}
+	if query.To != 0 {
+		createdAt["$lt"] = query.To
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	return filter
+}
+
+// mirrors TrendingScore and the other sort orders as aggregation expressions
+func sortExpression(sort string, now int64) interface{} {
+	commentCount := bson.M{"$ifNull": bson.A{"$commentCount", 0}}
+
+	switch sort {
+	case SortMostLiked:
+		return bson.M{"$ifNull": bson.A{"$likes", 0}}
+	case SortMostCommented:
+		return commentCount
+	case SortTrending:
+		activity := bson.M{"$add": bson.A{
+			bson.M{"$multiply": bson.A{2, bson.M{"$ifNull": bson.A{"$likes", 0}}}},
+			bson.M{"$multiply": bson.A{3, commentCount}},
+			bson.M{"$multiply": bson.A{-1, bson.M{"$ifNull": bson.A{"$dislikes", 0}}}},
+			1,
+		}}
+		ageHours := bson.M{"$max": bson.A{0, bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{now, "$createdAt"}}, 3600}}}}
+		return bson.M{"$divide": bson.A{activity, bson.M{"$pow": bson.A{bson.M{"$add": bson.A{ageHours, 2}}, 1.5}}}}
+	default:
+		return "$createdAt"
+	}
+}
+
+func (repo *mongoPostRepository) Search(ctx context.Context, query string, limit int, after *Cursor) (SearchPage, error) {
+	filter := bson.M{"$text": bson.M{"$search": query}, "hidden": bson.M{"$ne": true}}
+
+	total, err := repo.posts.CountDocuments(ctx, filter)
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if after != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"score": bson.M{"$lt": after.Value}},
+			bson.M{"score": after.Value, "_id": bson.M{"$lt": after.ID}},
+		}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}}},
+		// one more than asked for tells whether there is another page
+		bson.D{{Key: "$limit", Value: int64(limit + 1)}},
+	)
+	pipeline = append(pipeline, summaryStages("score")...)
+
+	cursor, err := repo.posts.Aggregate(ctx, pipeline)
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	var results []struct {
+		models.PostSummary `bson:",inline"`
+		Score              float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return SearchPage{}, err
+	}
+
+	hits := make([]SearchHit, 0, len(results))
+	for _, result := range results {
+		hits = append(hits, SearchHit{Post: result.PostSummary, Score: result.Score})
+	}
+	return searchPage(hits, total, limit), nil
+}
+
+func (repo *mongoPostRepository) Update(ctx context.Context, post models.Post) error {
+	filter := bson.M{"_id": post.ID, "userID": post.UserID}
+	update := bson.M{
+		"$set": bson.M{
+			"title":       post.Title,
+			"description": post.Description,
+			"video_url":   post.VideoURL,
+			"video":       post.Video,
+			"recipe":      post.Recipe,
+			"media":       post.Media,
+			"country":     post.Country,
+			"updatedAt":   time.Now().Unix(),
+		},
+	}
+
+	_, err := repo.posts.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (repo *mongoPostRepository) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	_, err := repo.posts.DeleteOne(ctx, bson.M{"_id": id, "userID": userID})
+	return err
+}
+
+func (repo *mongoPostRepository) DeleteAny(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.posts.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (repo *mongoPostRepository) SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error {
+	result, err := repo.posts.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"hidden": hidden}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (repo *mongoPostRepository) SetCommentCount(ctx context.Context, id primitive.ObjectID, count int) error {
+	_, err := repo.posts.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"commentCount": count}})
+	return err
+}
+
+func (repo *mongoPostRepository) SetAuthorName(ctx context.Context, userID primitive.ObjectID, name string) error {
+	_, err := repo.posts.UpdateMany(ctx, bson.M{"userID": userID}, bson.M{"$set": bson.M{"authorName": name}})
+	return err
+}
+
+func (repo *mongoPostRepository) SetMediaDetails(ctx context.Context, mediaID primitive.ObjectID, details models.ImageDetails) error {
+	update := bson.M{"$set": bson.M{
+		"media.$[m].width":    details.Width,
+		"media.$[m].height":   details.Height,
+		"media.$[m].blurhash": details.Blurhash,
+		"media.$[m].color":    details.Color,
+		"media.$[m].variants": details.Variants,
+	}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"m.id": mediaID}}})
+
+	_, err := repo.posts.UpdateMany(ctx, bson.M{"media.id": mediaID}, update, opts)
+	return err
+}
+
+func (repo *mongoPostRepository) AuthorStats(ctx context.Context, userID primitive.ObjectID) (int64, int64, error) {
+	cursor, err := repo.posts.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userID": userID, "hidden": bson.M{"$ne": true}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"posts": bson.M{"$sum": 1},
+			"likes": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$likes", 0}}},
+		}}},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var results []struct {
+		Posts int64 `bson:"posts"`
+		Likes int64 `bson:"likes"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+	return results[0].Posts, results[0].Likes, nil
+}
+
+func (repo *mongoPostRepository) AddReactionCount(ctx context.Context, id primitive.ObjectID, kind string, delta int) error {
+	inc := bson.M{"reactions." + kind: delta}
+	switch kind {
+	case models.ReactionLike:
+		inc["likes"] = delta
+	case models.ReactionDislike:
+		inc["dislikes"] = delta
+	}
+
+	_, err := repo.posts.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$inc": inc})
+	return err
+}
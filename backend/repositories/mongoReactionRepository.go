@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoReactionRepository struct {
+	reactions *mongo.Collection
+}
+
+// NewMongoReactionRepository returns a ReactionRepository backed by the "reactions"
+// collection, which has a unique index on userID, postID and kind
+func NewMongoReactionRepository(db *mongo.Database) ReactionRepository {
+	return &mongoReactionRepository{reactions: db.Collection("reactions")}
+}
+
+func (repo *mongoReactionRepository) Add(ctx context.Context, reaction models.Reaction) (bool, error) {
+	filter := bson.M{"userID": reaction.UserID, "postID": reaction.PostID, "kind": reaction.Kind}
+	update := bson.M{"$setOnInsert": bson.M{"createdAt": reaction.CreatedAt}}
+
+	result, err := repo.reactions.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	// two concurrent upserts can race on the unique index, in which case the
+	// other one added the reaction
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+func (repo *mongoReactionRepository) Remove(ctx context.Context, userID, postID primitive.ObjectID, kind string) (bool, error) {
+	result, err := repo.reactions.DeleteOne(ctx, bson.M{"userID": userID, "postID": postID, "kind": kind})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (repo *mongoReactionRepository) DeleteByPost(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := repo.reactions.DeleteMany(ctx, bson.M{"postID": postID})
+	return err
+}
+
+func (repo *mongoReactionRepository) KindsByUser(ctx context.Context, userID, postID primitive.ObjectID) ([]string, error) {
+	cursor, err := repo.reactions.Find(ctx, bson.M{"userID": userID, "postID": postID})
+	if err != nil {
+		return nil, err
+	}
+
+	var reactions []models.Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+
+	kinds := []string{}
+	for _, reaction := range reactions {
+		kinds = append(kinds, reaction.Kind)
+	}
+	return kinds, nil
+}
+
+func (repo *mongoReactionRepository) KindsByUserForPosts(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error) {
+	cursor, err := repo.reactions.Find(ctx, bson.M{"userID": userID, "postID": bson.M{"$in": postIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var reactions []models.Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+
+	kinds := make(map[primitive.ObjectID][]string)
+	for _, reaction := range reactions {
+		kinds[reaction.PostID] = append(kinds[reaction.PostID], reaction.Kind)
+	}
+	return kinds, nil
+}
+
+// ReconcileReactionCounts recomputes the per-kind reaction counters of every post,
+// along with likes and dislikes, from the reactions collection. Posts with
+// reactions are given their new counts in place, so none shows zero while it runs
+func ReconcileReactionCounts(ctx context.Context, db *mongo.Database) error {
+	countOf := func(kind string) bson.M {
+		return bson.M{"$ifNull": bson.A{"$reactions." + kind, 0}}
+	}
+
+	counted := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"postID": "$postID", "kind": "$kind"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$_id.postID",
+			"counts": bson.M{"$push": bson.M{"k": "$_id.kind", "v": "$count"}},
+		}}},
+		{{Key: "$project", Value: bson.M{"reactions": bson.M{"$arrayToObject": "$counts"}}}},
+		{{Key: "$addFields", Value: bson.M{
+			"likes":    countOf(models.ReactionLike),
+			"dislikes": countOf(models.ReactionDislike),
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           "posts",
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+
+	cursor, err := db.Collection("reactions").Aggregate(ctx, counted)
+	if err != nil {
+		return err
+	}
+	if err := cursor.Close(ctx); err != nil {
+		return err
+	}
+
+	// only then are the posts whose reactions were all removed, and which still
+	// show counts, set back to zero
+	uncounted := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"likes": bson.M{"$ne": 0}},
+			bson.M{"dislikes": bson.M{"$ne": 0}},
+			bson.M{"reactions": bson.M{"$nin": bson.A{bson.M{}, nil}}},
+		}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "reactions",
+			"let":  bson.M{"postID": "$_id"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$postID", "$$postID"}}}}},
+				{{Key: "$limit", Value: 1}},
+				{{Key: "$project", Value: bson.M{"_id": 1}}},
+			},
+			"as": "reacted",
+		}}},
+		{{Key: "$match", Value: bson.M{"reacted": bson.M{"$size": 0}}}},
+		{{Key: "$project", Value: bson.M{
+			"reactions": bson.M{"$literal": bson.M{}},
+			"likes":     bson.M{"$literal": 0},
+			"dislikes":  bson.M{"$literal": 0},
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           "posts",
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+
+	cursor, err = db.Collection("posts").Aggregate(ctx, uncounted)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
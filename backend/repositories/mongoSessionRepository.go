@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessionRepository struct {
+	sessions *mongo.Collection
+}
+
+// NewMongoSessionRepository returns a SessionRepository backed by the "sessions" collection
+func NewMongoSessionRepository(db *mongo.Database) SessionRepository {
+	return &mongoSessionRepository{sessions: db.Collection("sessions")}
+}
+
+func (repo *mongoSessionRepository) Create(ctx context.Context, session models.Session) error {
+	_, err := repo.sessions.InsertOne(ctx, session)
+	return err
+}
+
+func (repo *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.Session, error) {
+	var session models.Session
+	err := repo.sessions.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return session, ErrNotFound
+	}
+	return session, err
+}
+
+// the old hash is part of the filter, so of two requests racing with the same
+// refresh token only one can rotate it
+func (repo *mongoSessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt int64) (bool, error) {
+	result, err := repo.sessions.UpdateOne(ctx,
+		bson.M{"_id": id, "refreshHash": oldHash, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"refreshHash": newHash, "previousHash": oldHash, "lastUsedAt": time.Now().Unix(), "expiresAt": expiresAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *mongoSessionRepository) ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	filter := bson.M{
+		"userID":    userID,
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": time.Now().Unix()},
+	}
+	cursor, err := repo.sessions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "lastUsedAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (repo *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.sessions.UpdateOne(ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now().Unix()}},
+	)
+	return err
+}
+
+func (repo *mongoSessionRepository) RevokeAllByUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := repo.sessions.UpdateMany(ctx,
+		bson.M{"userID": userID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now().Unix()}},
+	)
+	return err
+}
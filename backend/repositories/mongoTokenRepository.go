@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoTokenRepository struct {
+	tokens *mongo.Collection
+}
+
+// NewMongoTokenRepository returns a TokenRepository backed by the "userTokens" collection
+func NewMongoTokenRepository(db *mongo.Database) TokenRepository {
+	return &mongoTokenRepository{tokens: db.Collection("userTokens")}
+}
+
+func (repo *mongoTokenRepository) Create(ctx context.Context, token models.UserToken) error {
+	_, err := repo.tokens.InsertOne(ctx, token)
+	return err
+}
+
+// marking the token used in the same update that finds it keeps it single-use
+// even when two requests race with it
+func (repo *mongoTokenRepository) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	now := time.Now().Unix()
+	filter := bson.M{
+		"purpose":   purpose,
+		"hash":      hash,
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+	}
+
+	var token models.UserToken
+	err := repo.tokens.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": now}}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return token, ErrNotFound
+	}
+	return token, err
+}
+
+func (repo *mongoTokenRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := repo.tokens.DeleteMany(ctx, bson.M{"userID": userID, "purpose": purpose})
+	return err
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUserRepository struct {
+	users *mongo.Collection
+}
+
+// NewMongoUserRepository returns a UserRepository backed by the "users" collection
+func NewMongoUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{users: db.Collection("users")}
+}
+
+func (repo *mongoUserRepository) Create(ctx context.Context, user models.User) (primitive.ObjectID, error) {
+	result, err := repo.users.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return primitive.NilObjectID, ErrHandleTaken
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+func (repo *mongoUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	return repo.findOne(ctx, bson.M{"_id": id})
+}
+
+func (repo *mongoUserRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	cursor, err := repo.users.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (repo *mongoUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	return repo.findOne(ctx, bson.M{"email": email})
+}
+
+func (repo *mongoUserRepository) FindByHandle(ctx context.Context, handle string) (models.User, error) {
+	return repo.findOne(ctx, bson.M{"handle": handle})
+}
+
+// relies on the unique index on handle created in config.ConnectDB
+func (repo *mongoUserRepository) UpdateProfile(ctx context.Context, id primitive.ObjectID, profile models.ProfileUpdate) error {
+	err := repo.set(ctx, id, bson.M{
+		"name":    profile.Name,
+		"handle":  profile.Handle,
+		"bio":     profile.Bio,
+		"avatar":  profile.Avatar,
+		"country": profile.Country,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrHandleTaken
+	}
+	return err
+}
+
+func (repo *mongoUserRepository) List(ctx context.Context, skip, limit int) ([]models.User, int64, error) {
+	total, err := repo.users.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := repo.users.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (repo *mongoUserRepository) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	return repo.set(ctx, id, bson.M{"role": role})
+}
+
+func (repo *mongoUserRepository) SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) error {
+	return repo.set(ctx, id, bson.M{"password": hashedPassword})
+}
+
+func (repo *mongoUserRepository) SetEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	return repo.set(ctx, id, bson.M{"emailVerified": true})
+}
+
+func (repo *mongoUserRepository) SetTwoFactor(ctx context.Context, id primitive.ObjectID, twoFactor models.TwoFactor) error {
+	return repo.set(ctx, id, bson.M{"twoFactor": twoFactor})
+}
+
+// the conditions are part of the filter so that two requests racing with the
+// same code cannot both succeed
+func (repo *mongoUserRepository) UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	result, err := repo.users.UpdateOne(ctx,
+		bson.M{"_id": id, "$or": bson.A{
+			bson.M{"twoFactor.lastStep": bson.M{"$exists": false}},
+			bson.M{"twoFactor.lastStep": bson.M{"$lt": step}},
+		}},
+		bson.M{"$set": bson.M{"twoFactor.lastStep": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *mongoUserRepository) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	result, err := repo.users.UpdateOne(ctx,
+		bson.M{"_id": id, "twoFactor.recoveryCodes": hash},
+		bson.M{"$pull": bson.M{"twoFactor.recoveryCodes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (repo *mongoUserRepository) RecordFailedLogin(ctx context.Context, id primitive.ObjectID) (int, error) {
+	var user models.User
+	err := repo.users.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$inc": bson.M{"failedLogins": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"failedLogins": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return 0, ErrNotFound
+	}
+	return user.FailedLogins, err
+}
+
+func (repo *mongoUserRepository) LockUntil(ctx context.Context, id primitive.ObjectID, until int64) error {
+	return repo.set(ctx, id, bson.M{"lockedUntil": until, "failedLogins": 0})
+}
+
+func (repo *mongoUserRepository) ResetFailedLogins(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repo.users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"failedLogins": "", "lockedUntil": ""}})
+	return err
+}
+
+func (repo *mongoUserRepository) set(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	result, err := repo.users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (repo *mongoUserRepository) findOne(ctx context.Context, filter bson.M) (models.User, error) {
+	var user models.User
+	err := repo.users.FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return user, ErrNotFound
+	}
+	return user, err
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"math"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// orders a post listing can be sorted in, all descending
+const (
+	SortNewest        = "newest"
+	SortMostLiked     = "most_liked"
+	SortMostCommented = "most_commented"
+	SortTrending      = "trending"
+)
+
+// PostQuery describes which posts to list and in what order
+type PostQuery struct {
+	Country    string
+	AuthorID   primitive.ObjectID
+	Ingredient string
+	From       int64 // unix seconds, inclusive
+	To         int64 // unix seconds, exclusive
+	ExcludeID  primitive.ObjectID
+	Sort       string
+	Limit      int
+	After      *Cursor
+
+	// when either is set, only posts by one of FollowedAuthors or from one of
+	// FollowedCountries are listed
+	FollowedAuthors   []primitive.ObjectID
+	FollowedCountries []string
+}
+
+// Cursor marks the last post of a page, by its sort value and ID
+type Cursor struct {
+	Value float64            `json:"v"`
+	ID    primitive.ObjectID `json:"id"`
+	// the time trending scores were computed at, so that scores do not shift between pages
+	Now int64 `json:"now,omitempty"`
+}
+
+// PostPage is one page of a post listing
+type PostPage struct {
+	Posts []models.PostSummary
+	Total int64
+	Next  *Cursor
+}
+
+// SearchPage is one page of search results; its cursor holds the last hit's score
+type SearchPage struct {
+	Hits  []SearchHit
+	Total int64
+	Next  *Cursor
+}
+
+// CommentPage is one page of a post's comment threads
+type CommentPage struct {
+	Threads []models.CommentThread
+	Total   int64
+	Next    *Cursor
+}
+
+// TrendingScore favours posts with many reactions and comments, decaying with age
+func TrendingScore(post models.Post, now int64) float64 {
+	ageHours := float64(now-post.CreatedAt) / 3600
+	if ageHours < 0 {
+		ageHours = 0
+	}
+	activity := float64(2*post.Likes+3*post.CommentCount-post.Dislikes) + 1
+	return activity / math.Pow(ageHours+2, 1.5)
+}
+
+// fills in the reference time used for trending scores
+func (query *PostQuery) now() int64 {
+	if query.After != nil && query.After.Now != 0 {
+		return query.After.Now
+	}
+	return time.Now().Unix()
+}
+
+// trims hits fetched one past the limit to a page, pointing the cursor at its last hit
+func searchPage(hits []SearchHit, total int64, limit int) SearchPage {
+	page := SearchPage{Hits: hits, Total: total}
+	if len(hits) > limit {
+		page.Hits = hits[:limit]
+		last := page.Hits[len(page.Hits)-1]
+		page.Next = &Cursor{Value: last.Score, ID: last.Post.ID}
+	}
+	return page
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound is returned when a lookup by ID or email matches nothing
+var ErrNotFound = errors.New("not found")
+
+// ErrHandleTaken is returned when another user already has the handle
+var ErrHandleTaken = errors.New("handle taken")
+
+// SearchHit is a post matched by a full-text search together with its relevance score
+type SearchHit struct {
+	Post  models.PostSummary
+	Score float64
+}
+
+// PostRepository stores and queries recipe posts
+type PostRepository interface {
+	Create(ctx context.Context, post models.Post) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Post, error)
+	List(ctx context.Context, query PostQuery) (PostPage, error)
+	// Summaries looks up the given posts as they appear in listings, leaving out
+	// hidden and deleted ones
+	Summaries(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.PostSummary, error)
+	// Search pages through the posts matching the query, best match first
+	Search(ctx context.Context, query string, limit int, after *Cursor) (SearchPage, error)
+	// Update replaces the post's editable fields when it belongs to post.UserID
+	Update(ctx context.Context, post models.Post) error
+	// Delete removes the post only when it belongs to userID
+	Delete(ctx context.Context, id, userID primitive.ObjectID) error
+	// DeleteAny removes the post whoever wrote it, for moderators
+	DeleteAny(ctx context.Context, id primitive.ObjectID) error
+	// SetHidden hides the post from listings and search, or shows it again
+	SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error
+	SetCommentCount(ctx context.Context, id primitive.ObjectID, count int) error
+	// AddReactionCount adds delta to the post's count of the reaction kind, keeping
+	// likes and dislikes in step
+	AddReactionCount(ctx context.Context, id primitive.ObjectID, kind string, delta int) error
+	// SetAuthorName updates the name stored on every post by the user
+	SetAuthorName(ctx context.Context, userID primitive.ObjectID, name string) error
+	// SetMediaDetails copies what processing found about an image onto every post it is attached to
+	SetMediaDetails(ctx context.Context, mediaID primitive.ObjectID, details models.ImageDetails) error
+	// AuthorStats counts the user's visible posts and the likes they have received
+	AuthorStats(ctx context.Context, userID primitive.ObjectID) (posts int64, likes int64, err error)
+}
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	Create(ctx context.Context, user models.User) (primitive.ObjectID, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.User, error)
+	// FindByIDs returns the users with the given IDs in no particular order; IDs
+	// without a user are left out
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	FindByHandle(ctx context.Context, handle string) (models.User, error)
+	// UpdateProfile returns ErrHandleTaken when the new handle belongs to someone else
+	UpdateProfile(ctx context.Context, id primitive.ObjectID, profile models.ProfileUpdate) error
+	List(ctx context.Context, skip, limit int) ([]models.User, int64, error)
+	SetRole(ctx context.Context, id primitive.ObjectID, role string) error
+	SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) error
+	SetEmailVerified(ctx context.Context, id primitive.ObjectID) error
+	SetTwoFactor(ctx context.Context, id primitive.ObjectID, twoFactor models.TwoFactor) error
+	// UseTOTPStep records a TOTP code's time step as used, reporting false when
+	// that step or a later one has been used already
+	UseTOTPStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error)
+	// UseRecoveryCode removes the recovery code with the given hash, reporting false when there is none
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error)
+	// RecordFailedLogin counts a failed sign-in and returns how many there have been in a row
+	RecordFailedLogin(ctx context.Context, id primitive.ObjectID) (int, error)
+	// LockUntil stops the account signing in before the given unix time and starts the count again
+	LockUntil(ctx context.Context, id primitive.ObjectID, until int64) error
+	// ResetFailedLogins clears the failure count and any lock
+	ResetFailedLogins(ctx context.Context, id primitive.ObjectID) error
+}
+
+// SessionRepository stores the refresh sessions behind issued access tokens
+type SessionRepository interface {
+	Create(ctx context.Context, session models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Session, error)
+	// Rotate swaps the refresh token hash, reporting false when oldHash is no
+	// longer current or the session has been revoked
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt int64) (bool, error)
+	ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error)
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	RevokeAllByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// APIKeyRepository stores users' personal API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, key models.APIKey) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.APIKey, error)
+	ListActiveByUser(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error)
+	// Touch records that the key has just been used
+	Touch(ctx context.Context, id primitive.ObjectID) error
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+}
+
+// TokenRepository stores the single-use tokens sent in account emails
+type TokenRepository interface {
+	Create(ctx context.Context, token models.UserToken) error
+	// Consume marks the unused, unexpired token with the given hash as used and
+	// returns it, or ErrNotFound when there is none
+	Consume(ctx context.Context, purpose, hash string) (models.UserToken, error)
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+// LoginEventRepository stores the audit trail of sign-in attempts
+type LoginEventRepository interface {
+	Create(ctx context.Context, event models.LoginEvent) error
+	// ListByUser returns the user's most recent events, newest first
+	ListByUser(ctx context.Context, userID primitive.ObjectID, limit int) ([]models.LoginEvent, error)
+}
+
+// FollowRepository stores which cooks and countries each user follows
+type FollowRepository interface {
+	// Add is idempotent: following something twice changes nothing
+	Add(ctx context.Context, follow models.Follow) error
+	// Remove reports whether the follow existed
+	Remove(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error)
+	Exists(ctx context.Context, followerID primitive.ObjectID, kind, target string) (bool, error)
+	// ListByFollower returns everything the user follows, most recent first
+	ListByFollower(ctx context.Context, followerID primitive.ObjectID) ([]models.Follow, error)
+	CountFollowers(ctx context.Context, kind, target string) (int64, error)
+	CountFollowing(ctx context.Context, followerID primitive.ObjectID, kind string) (int64, error)
+}
+
+// CollectionRepository stores users' collections of saved posts
+type CollectionRepository interface {
+	Create(ctx context.Context, collection models.Collection) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Collection, error)
+	// ListByUser returns the user's collections, most recently changed first
+	ListByUser(ctx context.Context, userID primitive.ObjectID, publicOnly bool) ([]models.Collection, error)
+	CountByUser(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	UpdateDetails(ctx context.Context, id primitive.ObjectID, name, description string, public bool) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// AddItem appends the post, reporting false when it is in the collection already
+	AddItem(ctx context.Context, id primitive.ObjectID, item models.CollectionItem) (bool, error)
+	// SetItemNote reports false when the post is not in the collection
+	SetItemNote(ctx context.Context, id, postID primitive.ObjectID, note string) (bool, error)
+	// RemoveItem reports false when the post is not in the collection
+	RemoveItem(ctx context.Context, id, postID primitive.ObjectID) (bool, error)
+	// SetItems replaces the items, which is how they are reordered
+	SetItems(ctx context.Context, id primitive.ObjectID, items []models.CollectionItem) error
+	// RemovePost takes a deleted post out of every collection
+	RemovePost(ctx context.Context, postID primitive.ObjectID) error
+}
+
+// MediaRepository stores the records of uploaded files
+type MediaRepository interface {
+	Create(ctx context.Context, media models.Media) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Media, error)
+	// ListPending returns the images whose variants have not been made yet
+	ListPending(ctx context.Context) ([]models.Media, error)
+	SetImageDetails(ctx context.Context, id primitive.ObjectID, status string, details models.ImageDetails) error
+}
+
+// ReactionRepository stores one document per user, post and reaction kind
+type ReactionRepository interface {
+	// Add is idempotent: adding a reaction the user already has changes nothing.
+	// It reports whether the reaction is new
+	Add(ctx context.Context, reaction models.Reaction) (bool, error)
+	// Remove reports whether the reaction existed
+	Remove(ctx context.Context, userID, postID primitive.ObjectID, kind string) (bool, error)
+	KindsByUser(ctx context.Context, userID, postID primitive.ObjectID) ([]string, error)
+	// KindsByUserForPosts is KindsByUser for several posts at once, keyed by post
+	KindsByUserForPosts(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) (map[primitive.ObjectID][]string, error)
+	DeleteByPost(ctx context.Context, postID primitive.ObjectID) error
+}
+
+// CommentRepository stores comments and the replies made to them
+type CommentRepository interface {
+	Create(ctx context.Context, comment models.Comment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (models.Comment, error)
+	UpdateText(ctx context.Context, id primitive.ObjectID, text string) error
+	// SoftDelete clears the comment's text but keeps it as a placeholder in its thread
+	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	SetHidden(ctx context.Context, id primitive.ObjectID, hidden bool) error
+	DeleteByPost(ctx context.Context, postID primitive.ObjectID) error
+	// ListThreads pages through a post's top-level comments, newest first,
+	// returning each with all of its replies
+	ListThreads(ctx context.Context, postID primitive.ObjectID, limit int, after *Cursor) (CommentPage, error)
+	// CountByPost counts the comments of a post that have not been deleted or hidden
+	CountByPost(ctx context.Context, postID primitive.ObjectID) (int, error)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/gorilla/mux"
+)
+
+func AdminRoutes(router *mux.Router, app *controllers.App) {
+	admins := router.PathPrefix("/admin/users").Subrouter()
+	admins.Use(middlewares.AuthMiddleware(app.Sessions, app.APIKeys))
+	admins.Use(middlewares.RequireSession)
+	admins.Use(middlewares.RequireRole(models.RoleAdmin))
+
+	admins.HandleFunc("", app.ListUsers).Methods("GET")
+	admins.HandleFunc("/{id}/role", app.SetUserRole).Methods("PATCH")
+
+	moderators := router.PathPrefix("/admin").Subrouter()
+	moderators.Use(middlewares.AuthMiddleware(app.Sessions, app.APIKeys))
+	moderators.Use(middlewares.RequireSession)
+	moderators.Use(middlewares.RequireRole(models.RoleModerator, models.RoleAdmin))
+
+	moderators.HandleFunc("/posts/{id}", app.AdminDeletePost).Methods("DELETE")
+	moderators.HandleFunc("/posts/{id}", app.SetPostHidden).Methods("PATCH")
+	moderators.HandleFunc("/comments/{id}", app.AdminDeleteComment).Methods("DELETE")
+	moderators.HandleFunc("/comments/{id}", app.SetCommentHidden).Methods("PATCH")
+}
@@ -2,12 +2,34 @@ package routes
 
 import (
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
 	"github.com/gorilla/mux"
 )
 
-func AuthRoutes(router *mux.Router) {
-	router.HandleFunc("/auth/signup", controllers.Signup).Methods("POST")
-	router.HandleFunc("/auth/login", controllers.Login).Methods("POST")
-	router.HandleFunc("/auth/logout", controllers.Logout).Methods("POST")
-	router.HandleFunc("/auth/validate", controllers.ValidateToken).Methods("GET")
+func AuthRoutes(router *mux.Router, app *controllers.App) {
+	router.HandleFunc("/auth/signup", app.Signup).Methods("POST")
+	router.HandleFunc("/auth/login", app.Login).Methods("POST")
+	router.HandleFunc("/auth/logout", app.Logout).Methods("POST")
+	router.HandleFunc("/auth/refresh", app.RefreshToken).Methods("POST")
+	router.HandleFunc("/auth/validate", app.ValidateToken).Methods("GET")
+	router.HandleFunc("/auth/forgot-password", app.ForgotPassword).Methods("POST")
+	router.HandleFunc("/auth/reset-password", app.ResetPassword).Methods("POST")
+	router.HandleFunc("/auth/verify-email", app.VerifyEmail).Methods("POST")
+	router.HandleFunc("/auth/2fa/verify", app.VerifyTwoFactor).Methods("POST")
+
+	authRequired := router.PathPrefix("/auth").Subrouter()
+	authRequired.Use(middlewares.AuthMiddleware(app.Sessions, app.APIKeys))
+	authRequired.Use(middlewares.RequireSession)
+
+	authRequired.HandleFunc("/verify-email/resend", app.ResendVerification).Methods("POST")
+	authRequired.HandleFunc("/sessions", app.GetSessions).Methods("GET")
+	authRequired.HandleFunc("/sessions/{id}", app.RevokeSession).Methods("DELETE")
+	authRequired.HandleFunc("/login-history", app.GetLoginHistory).Methods("GET")
+	authRequired.HandleFunc("/api-keys", app.CreateAPIKey).Methods("POST")
+	authRequired.HandleFunc("/api-keys", app.GetAPIKeys).Methods("GET")
+	authRequired.HandleFunc("/api-keys/{id}", app.RevokeAPIKey).Methods("DELETE")
+	authRequired.HandleFunc("/2fa/setup", app.SetupTwoFactor).Methods("POST")
+	authRequired.HandleFunc("/2fa/enable", app.EnableTwoFactor).Methods("POST")
+	authRequired.HandleFunc("/2fa/disable", app.DisableTwoFactor).Methods("POST")
+	authRequired.HandleFunc("/2fa/recovery-codes", app.RegenerateRecoveryCodes).Methods("POST")
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/gorilla/mux"
+)
+
+func CollectionRoutes(router *mux.Router, app *controllers.App) {
+	optionalAuth := middlewares.OptionalAuth(app.Sessions, app.APIKeys)
+	authRequired := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.AuthMiddleware(app.Sessions, app.APIKeys)(middlewares.RequireSession(handler))
+	}
+
+	// registered before /users/{id}/collections, which would match "me" as well
+	router.Handle("/users/me/collections", authRequired(app.GetMyCollections)).Methods("GET")
+	router.Handle("/users/me/collections", authRequired(app.CreateCollection)).Methods("POST")
+	router.Handle("/users/me/collections/{id}", authRequired(app.GetCollection)).Methods("GET")
+	router.Handle("/users/me/collections/{id}", authRequired(app.UpdateCollection)).Methods("PATCH")
+	router.Handle("/users/me/collections/{id}", authRequired(app.DeleteCollection)).Methods("DELETE")
+	router.Handle("/users/me/collections/{id}/items", authRequired(app.AddCollectionItem)).Methods("POST")
+	router.Handle("/users/me/collections/{id}/items", authRequired(app.ReorderCollection)).Methods("PUT")
+	router.Handle("/users/me/collections/{id}/items/{postId}", authRequired(app.UpdateCollectionItem)).Methods("PATCH")
+	router.Handle("/users/me/collections/{id}/items/{postId}", authRequired(app.RemoveCollectionItem)).Methods("DELETE")
+
+	// the shareable link of a public collection
+	router.Handle("/collections/{id}", optionalAuth(http.HandlerFunc(app.GetCollection))).Methods("GET")
+	router.HandleFunc("/users/{id}/collections", app.GetUserCollections).Methods("GET")
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/gorilla/mux"
+)
+
+func FeedRoutes(router *mux.Router, app *controllers.App) {
+	auth := middlewares.AuthMiddleware(app.Sessions, app.APIKeys)
+
+	// reading the feed is reading posts, so API keys with posts:read may do it
+	router.Handle("/feed", auth(middlewares.RequireScope(models.ScopePostsRead)(http.HandlerFunc(app.GetFeed)))).Methods("GET")
+
+	router.Handle("/countries/{country}/follow", auth(middlewares.RequireSession(http.HandlerFunc(app.FollowCountry)))).Methods("POST")
+	router.Handle("/countries/{country}/follow", auth(middlewares.RequireSession(http.HandlerFunc(app.UnfollowCountry)))).Methods("DELETE")
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/gorilla/mux"
+)
+
+func MediaRoutes(router *mux.Router, app *controllers.App) {
+	upload := middlewares.RequireScope(models.ScopePostsWrite)(http.HandlerFunc(app.UploadMedia))
+	router.Handle("/media", middlewares.AuthMiddleware(app.Sessions, app.APIKeys)(upload)).Methods("POST")
+
+	// stores that keep files on this machine serve them too; others have their own URLs
+	if handler, ok := app.Blobs.(http.Handler); ok {
+		router.PathPrefix("/media/").Handler(http.StripPrefix("/media", handler)).Methods("GET", "HEAD")
+	}
+}
@@ -1,25 +1,45 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
 	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
 	"github.com/gorilla/mux"
 )
 
-func PostRoutes(router *mux.Router) {
+func PostRoutes(router *mux.Router, app *controllers.App) {
+
+	// public routes still identify signed-in users, to personalize what they see
+	optionalAuth := middlewares.OptionalAuth(app.Sessions, app.APIKeys)
 
-	router.HandleFunc("/posts", controllers.GetPosts).Methods("GET")
-	router.HandleFunc("/posts/{id}", controllers.GetPost).Methods("GET")
-	router.HandleFunc("/posts/{id}/related", controllers.GetRelatedPosts).Methods("GET")
-	router.HandleFunc("/posts/country/{country}", controllers.GetPostsByCountry).Methods("GET")
+	router.Handle("/posts", optionalAuth(http.HandlerFunc(app.GetPosts))).Methods("GET")
+	router.Handle("/posts/search/{query}", optionalAuth(http.HandlerFunc(app.SearchPosts))).Methods("GET")
+	router.Handle("/posts/{id}", optionalAuth(http.HandlerFunc(app.GetPost))).Methods("GET")
+	router.Handle("/posts/{id}/related", optionalAuth(http.HandlerFunc(app.GetRelatedPosts))).Methods("GET")
+	router.HandleFunc("/posts/{id}/recipe", app.GetRecipe).Methods("GET")
+	router.HandleFunc("/posts/{id}/comments", app.GetComments).Methods("GET")
+	router.Handle("/posts/country/{country}", optionalAuth(http.HandlerFunc(app.GetPostsByCountry))).Methods("GET")
 
 	authRequired := router.PathPrefix("/posts").Subrouter()
-	authRequired.Use(middlewares.AuthMiddleware)
-
-	authRequired.HandleFunc("", controllers.CreatePost).Methods("POST")
-	authRequired.HandleFunc("/{id}", controllers.UpdatePost).Methods("PUT")
-	authRequired.HandleFunc("/{id}", controllers.DeletePost).Methods("DELETE")
-	authRequired.HandleFunc("/{id}/comments", controllers.AddComment).Methods("POST")
-	authRequired.HandleFunc("/{id}/like", controllers.LikePost).Methods("POST")
-	authRequired.HandleFunc("/{id}/dislike", controllers.DislikePost).Methods("POST")
+	authRequired.Use(middlewares.AuthMiddleware(app.Sessions, app.APIKeys))
+
+	// API keys are limited to the routes their scopes cover
+	postsWrite := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.RequireScope(models.ScopePostsWrite)(handler)
+	}
+	commentsWrite := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.RequireScope(models.ScopeCommentsWrite)(handler)
+	}
+
+	authRequired.Handle("", postsWrite(app.CreatePost)).Methods("POST")
+	authRequired.Handle("/{id}", postsWrite(app.UpdatePost)).Methods("PATCH")
+	authRequired.Handle("/{id}", postsWrite(app.DeletePost)).Methods("DELETE")
+	authRequired.Handle("/{id}/comments", commentsWrite(app.AddComment)).Methods("POST")
+	authRequired.Handle("/{id}/comments/{commentId}", commentsWrite(app.UpdateComment)).Methods("PATCH")
+	authRequired.Handle("/{id}/comments/{commentId}", commentsWrite(app.DeleteComment)).Methods("DELETE")
+	authRequired.Handle("/{id}/reactions", postsWrite(app.ReactToPost)).Methods("POST")
+	authRequired.Handle("/{id}/like", postsWrite(app.LikePost)).Methods("POST")
+	authRequired.Handle("/{id}/dislike", postsWrite(app.DislikePost)).Methods("POST")
 }
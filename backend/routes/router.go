@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/apperror"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/gorilla/mux"
+)
+
+// builds the complete HTTP API for the given application
+func NewRouter(app *controllers.App) http.Handler {
+	router := mux.NewRouter()
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apperror.Write(w, apperror.New(apperror.NotFound, "Not found"))
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apperror.Write(w, apperror.New(apperror.MethodNotAllowed, "Method not allowed"))
+	})
+
+	AuthRoutes(router, app)
+	PostRoutes(router, app)
+	UserRoutes(router, app)
+	FeedRoutes(router, app)
+	CollectionRoutes(router, app)
+	MediaRoutes(router, app)
+	AdminRoutes(router, app)
+
+	return middlewares.CORS(router)
+}
@@ -0,0 +1,709 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/mailer"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/repositories"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/storage"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/utils"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pquerna/otp/totp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the whole API, backed by memory repositories
+func newTestServer(t *testing.T) http.Handler {
+	return NewRouter(newTestApp(t))
+}
+
+func newTestApp(t *testing.T) *controllers.App {
+	t.Helper()
+	utils.JwtSecretKey = []byte("test secret")
+
+	users := repositories.NewMemoryUserRepository()
+	return &controllers.App{
+		Posts:       repositories.NewMemoryPostRepository(users),
+		Users:       users,
+		Sessions:    repositories.NewMemorySessionRepository(),
+		APIKeys:     repositories.NewMemoryAPIKeyRepository(),
+		Reactions:   repositories.NewMemoryReactionRepository(),
+		Comments:    repositories.NewMemoryCommentRepository(),
+		Follows:     repositories.NewMemoryFollowRepository(),
+		Collections: repositories.NewMemoryCollectionRepository(),
+		Media:       repositories.NewMemoryMediaRepository(),
+		Tokens:      repositories.NewMemoryTokenRepository(),
+		LoginEvents: repositories.NewMemoryLoginEventRepository(),
+		Mailer:      mailer.NewMemoryMailer(),
+		Blobs:       storage.NewMemoryStore(),
+	}
+}
+
+type response struct {
+	Code int
+	Body map[string]interface{}
+}
+
+// the code of the error envelope, "" when the response is not an error
+func (res response) errorCode() string {
+	envelope, _ := res.Body["error"].(map[string]interface{})
+	code, _ := envelope["code"].(string)
+	return code
+}
+
+func call(t *testing.T, server http.Handler, method, path, token, body string) response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	res := response{Code: rec.Code}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res.Body); err != nil {
+		t.Fatalf("%s %s: response is not a JSON object: %q", method, path, rec.Body.String())
+	}
+	return res
+}
+
+// signs up a new user and returns their access token
+func signup(t *testing.T, server http.Handler, name, email string) string {
+	t.Helper()
+	res := call(t, server, "POST", "/auth/signup", "", `{"name":"`+name+`","email":"`+email+`","password":"secret12!"}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("signup %s: got %d %v", email, res.Code, res.Body)
+	}
+	return res.Body["token"].(string)
+}
+
+func TestSignupAndLogin(t *testing.T) {
+	server := newTestServer(t)
+	signup(t, server, "Amina", "amina@example.com")
+
+	res := call(t, server, "POST", "/auth/signup", "", `{"name":"Amina","email":"amina@example.com","password":"secret12!"}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "VALIDATION_FAILED" {
+		t.Errorf("signup with a taken email: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "POST", "/auth/signup", "", `{"name":"","email":"not an email","password":"short"}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "VALIDATION_FAILED" {
+		t.Errorf("invalid signup: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "POST", "/auth/signup", "", `{"name":"Bilal","email":"bilal@example.com","password":"secret12!","avatar":"javascript:alert(1)"}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "VALIDATION_FAILED" {
+		t.Errorf("signup with a javascript: avatar: got %d %v", res.Code, res.Body)
+	}
+
+	id := "000000000000000000000001"
+	res = call(t, server, "POST", "/auth/signup", "", `{"ID":"`+id+`","name":"Bilal","email":"bilal@example.com","password":"secret12!","role":"admin"}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("signup with extra fields: got %d %v", res.Code, res.Body)
+	}
+	res = call(t, server, "GET", "/users/me", res.Body["token"].(string), "")
+	if res.Body["id"] == id || res.Body["role"] != "user" {
+		t.Errorf("signup set fields it should not: got %v", res.Body)
+	}
+
+	res = call(t, server, "POST", "/auth/login", "", `{"email":"amina@example.com","password":"secret12!"}`)
+	if res.Code != http.StatusOK || res.Body["token"] == "" {
+		t.Fatalf("login: got %d %v", res.Code, res.Body)
+	}
+
+	token := res.Body["token"].(string)
+	if res := call(t, server, "GET", "/users/me", token, ""); res.Code != http.StatusOK {
+		t.Errorf("profile with the login token: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "POST", "/auth/login", "", `{"email":"amina@example.com","password":"wrong password"}`)
+	if res.Code != http.StatusUnauthorized || res.errorCode() != "INVALID_CREDENTIALS" {
+		t.Errorf("login with a wrong password: got %d %v", res.Code, res.Body)
+	}
+}
+
+func TestPostCRUD(t *testing.T) {
+	server := newTestServer(t)
+	token := signup(t, server, "Amina", "amina@example.com")
+
+	res := call(t, server, "POST", "/posts", token, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	id := res.Body["ID"].(string)
+	if res.Body["Country"] != "Pakistan" {
+		t.Errorf("create: country stored as %v, want Pakistan", res.Body["Country"])
+	}
+
+	res = call(t, server, "GET", "/posts/"+id, "", "")
+	if res.Code != http.StatusOK || res.Body["Title"] != "Karahi" {
+		t.Errorf("get: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "POST", "/posts", token, `{"title":"Nihari","country":"pk","recipe":"1 kg beef",`+
+		`"Likes":500,"Reactions":{"like":500},"CommentCount":9,"Hidden":true,"CreatedAt":1,"UpdatedAt":1}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("create with counters: got %d %v", res.Code, res.Body)
+	}
+	for _, field := range []string{"Likes", "Reactions", "CommentCount", "Hidden", "UpdatedAt"} {
+		if value, ok := res.Body[field]; ok && value != float64(0) && value != false && value != nil {
+			t.Errorf("create took %s = %v from the body", field, value)
+		}
+	}
+	if res.Body["CreatedAt"] == float64(1) {
+		t.Errorf("create took CreatedAt from the body")
+	}
+	call(t, server, "DELETE", "/posts/"+res.Body["ID"].(string), token, "")
+
+	res = call(t, server, "PATCH", "/posts/"+id, token, `{"title":"Chicken Karahi"}`)
+	if res.Code != http.StatusOK || res.Body["Title"] != "Chicken Karahi" || res.Body["Country"] != "Pakistan" {
+		t.Errorf("update: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "GET", "/posts", "", "")
+	if res.Code != http.StatusOK || res.Body["total"] != float64(1) {
+		t.Errorf("list: got %d %v", res.Code, res.Body)
+	}
+	for _, path := range []string{"/posts?country=pk", "/posts?country=PAKISTAN", "/posts/country/pk"} {
+		if res := call(t, server, "GET", path, "", ""); res.Code != http.StatusOK || res.Body["total"] != float64(1) {
+			t.Errorf("%s: got %d %v", path, res.Code, res.Body)
+		}
+	}
+	if res := call(t, server, "GET", "/posts?country=Atlantis", "", ""); res.Body["total"] != float64(0) {
+		t.Errorf("unknown country: got %d %v", res.Code, res.Body)
+	}
+
+	if res := call(t, server, "DELETE", "/posts/"+id, token, ""); res.Code != http.StatusOK {
+		t.Errorf("delete: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/posts/"+id, "", ""); res.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d %v", res.Code, res.Body)
+	}
+}
+
+func TestPostErrors(t *testing.T) {
+	server := newTestServer(t)
+	owner := signup(t, server, "Amina", "amina@example.com")
+	other := signup(t, server, "Bilal", "bilal@example.com")
+
+	res := call(t, server, "POST", "/posts", owner, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	id := res.Body["ID"].(string)
+	missing := "000000000000000000000001"
+
+	tests := []struct {
+		name         string
+		method, path string
+		token, body  string
+		status       int
+		code         string
+	}{
+		{"create without a token", "POST", "/posts", "", `{"title":"x"}`, http.StatusUnauthorized, "UNAUTHENTICATED"},
+		{"create an invalid post", "POST", "/posts", owner, `{"title":""}`, http.StatusBadRequest, "VALIDATION_FAILED"},
+		{"get a missing post", "GET", "/posts/" + missing, "", "", http.StatusNotFound, "POST_NOT_FOUND"},
+		{"get a malformed ID", "GET", "/posts/nope", "", "", http.StatusBadRequest, "INVALID_ID"},
+		{"update a missing post", "PATCH", "/posts/" + missing, owner, `{"title":"x"}`, http.StatusNotFound, "POST_NOT_FOUND"},
+		{"update another user's post", "PATCH", "/posts/" + id, other, `{"title":"x"}`, http.StatusForbidden, "FORBIDDEN"},
+		{"delete a missing post", "DELETE", "/posts/" + missing, owner, "", http.StatusNotFound, "POST_NOT_FOUND"},
+		{"delete another user's post", "DELETE", "/posts/" + id, other, "", http.StatusForbidden, "FORBIDDEN"},
+		{"unknown route", "GET", "/nowhere", "", "", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := call(t, server, test.method, test.path, test.token, test.body)
+			if res.Code != test.status || res.errorCode() != test.code {
+				t.Errorf("got %d %q, want %d %q", res.Code, res.errorCode(), test.status, test.code)
+			}
+		})
+	}
+
+	if res := call(t, server, "GET", "/posts/"+id, "", ""); res.Code != http.StatusOK || res.Body["Title"] != "Karahi" {
+		t.Errorf("the post changed after the failed requests: got %d %v", res.Code, res.Body)
+	}
+}
+
+func TestReactionCounts(t *testing.T) {
+	app := newTestApp(t)
+	server := NewRouter(app)
+	token := signup(t, server, "Amina", "amina@example.com")
+
+	res := call(t, server, "POST", "/posts", token, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	id := res.Body["ID"].(string)
+
+	steps := []struct {
+		path            string
+		likes, dislikes float64
+	}{
+		{"/like", 1, 0},
+		{"/dislike", 0, 1},
+		{"/dislike", 0, 0},
+		{"/like", 1, 0},
+	}
+	for _, step := range steps {
+		res := call(t, server, "POST", "/posts/"+id+step.path, token, "")
+		if res.Code != http.StatusOK || res.Body["likes"] != step.likes || res.Body["dislikes"] != step.dislikes {
+			t.Fatalf("POST %s: got %d %v, want %v likes and %v dislikes", step.path, res.Code, res.Body, step.likes, step.dislikes)
+		}
+	}
+
+	res = call(t, server, "GET", "/posts/"+id, "", "")
+	if res.Body["Likes"] != float64(1) || res.Body["Dislikes"] != float64(0) {
+		t.Errorf("stored counts: got %v likes and %v dislikes", res.Body["Likes"], res.Body["Dislikes"])
+	}
+
+	// the reactions go with the post
+	if res := call(t, server, "DELETE", "/posts/"+id, token, ""); res.Code != http.StatusOK {
+		t.Fatalf("delete: got %d %v", res.Code, res.Body)
+	}
+	userID, _ := primitive.ObjectIDFromHex(call(t, server, "GET", "/users/me", token, "").Body["id"].(string))
+	postID, _ := primitive.ObjectIDFromHex(id)
+	if kinds, _ := app.Reactions.KindsByUser(context.Background(), userID, postID); len(kinds) != 0 {
+		t.Errorf("reactions left after delete: %v", kinds)
+	}
+}
+
+func TestFollowing(t *testing.T) {
+	server := newTestServer(t)
+	token := signup(t, server, "Amina", "amina@example.com")
+
+	ids := []string{}
+	for _, email := range []string{"bilal@example.com", "chen@example.com"} {
+		res := call(t, server, "GET", "/users/me", signup(t, server, "Cook", email), "")
+		ids = append(ids, res.Body["id"].(string))
+	}
+	for _, id := range []string{ids[1], ids[0]} {
+		if res := call(t, server, "POST", "/users/"+id+"/follow", token, ""); res.Code != http.StatusOK {
+			t.Fatalf("follow %s: got %d %v", id, res.Code, res.Body)
+		}
+	}
+	if res := call(t, server, "POST", "/countries/pk/follow", token, ""); res.Code != http.StatusOK {
+		t.Fatalf("follow a country: got %d %v", res.Code, res.Body)
+	}
+
+	res := call(t, server, "GET", "/users/me/following", token, "")
+	users, _ := res.Body["users"].([]interface{})
+	countries, _ := res.Body["countries"].([]interface{})
+	if res.Code != http.StatusOK || len(users) != 2 || len(countries) != 1 {
+		t.Fatalf("following: got %d %v", res.Code, res.Body)
+	}
+	got := map[interface{}]bool{}
+	for _, user := range users {
+		got[user.(map[string]interface{})["id"]] = true
+	}
+	for _, id := range ids {
+		if !got[id] {
+			t.Errorf("following: %s is missing from %v", id, users)
+		}
+	}
+}
+
+// posts saved before the current rules can still have their other fields changed
+func TestUpdateLegacyPost(t *testing.T) {
+	app := newTestApp(t)
+	server := NewRouter(app)
+	token := signup(t, server, "Amina", "amina@example.com")
+	userID, _ := primitive.ObjectIDFromHex(call(t, server, "GET", "/users/me", token, "").Body["id"].(string))
+
+	legacy := models.Post{ID: primitive.NewObjectID(), UserID: userID, Title: "Karahi", Country: "Atlantis", VideoURL: "ftp://example.com/karahi"}
+	if err := app.Posts.Create(context.Background(), legacy); err != nil {
+		t.Fatal(err)
+	}
+	path := "/posts/" + legacy.ID.Hex()
+
+	res := call(t, server, "PATCH", path, token, `{"title":"Chicken Karahi"}`)
+	if res.Code != http.StatusOK || res.Body["Title"] != "Chicken Karahi" || res.Body["Country"] != "Atlantis" {
+		t.Errorf("title-only update: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "PATCH", path, token, `{"country":"Atlantis"}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "VALIDATION_FAILED" {
+		t.Errorf("update with an unknown country: got %d %v", res.Code, res.Body)
+	}
+	if details, _ := res.Body["error"].(map[string]interface{})["details"].(map[string]interface{}); len(details) != 1 || details["country"] == nil {
+		t.Errorf("update with an unknown country: errors for fields that were not sent: %v", details)
+	}
+
+	if res := call(t, server, "PUT", path, token, `{"title":"Karahi"}`); res.Code < 400 {
+		t.Errorf("PUT is no longer routed: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", path, "", ""); res.Body["Title"] != "Chicken Karahi" {
+		t.Errorf("the post changed after the refused requests: got %v", res.Body)
+	}
+}
+
+func TestComments(t *testing.T) {
+	app := newTestApp(t)
+	server := NewRouter(app)
+	token := signup(t, server, "Amina", "amina@example.com")
+
+	res := call(t, server, "POST", "/posts", token, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	id := res.Body["ID"].(string)
+	// only moderators hide comments
+	if res := call(t, server, "POST", "/posts/"+id+"/comments", token, `{"text":"Lovely","Hidden":true}`); res.Code/100 != 2 {
+		t.Fatalf("comment: got %d %v", res.Code, res.Body)
+	}
+	res = call(t, server, "GET", "/posts/"+id+"/comments", "", "")
+	comments, _ := res.Body["comments"].([]interface{})
+	if res.Code != http.StatusOK || len(comments) != 1 || comments[0].(map[string]interface{})["Text"] != "Lovely" {
+		t.Fatalf("comments: got %d %v", res.Code, res.Body)
+	}
+
+	postID, _ := primitive.ObjectIDFromHex(id)
+	if err := app.Posts.SetHidden(context.Background(), postID, true); err != nil {
+		t.Fatal(err)
+	}
+	if res := call(t, server, "GET", "/posts/"+id+"/comments", "", ""); res.errorCode() != "POST_NOT_FOUND" {
+		t.Errorf("comments of a hidden post: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/posts/000000000000000000000001/comments", "", ""); res.errorCode() != "POST_NOT_FOUND" {
+		t.Errorf("comments of a missing post: got %d %v", res.Code, res.Body)
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	server := newTestServer(t)
+	token := signup(t, server, "Amina", "amina@example.com")
+
+	for _, title := range []string{"Karahi", "Chicken Karahi", "Karahi Karahi", "Lamb Karahi", "Nihari"} {
+		if res := call(t, server, "POST", "/posts", token, `{"title":"`+title+`","country":"pk","recipe":"1 kg meat"}`); res.Code != http.StatusOK {
+			t.Fatalf("create %s: got %d %v", title, res.Code, res.Body)
+		}
+	}
+
+	seen := map[interface{}]bool{}
+	lastScore := 1e9
+	path := "/posts/search/karahi?limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages > 3 {
+			t.Fatal("search does not run out of pages")
+		}
+		res := call(t, server, "GET", path, "", "")
+		if res.Code != http.StatusOK || res.Body["total"] != float64(4) {
+			t.Fatalf("%s: got %d %v", path, res.Code, res.Body)
+		}
+		for _, post := range res.Body["posts"].([]interface{}) {
+			post := post.(map[string]interface{})
+			if seen[post["ID"]] {
+				t.Errorf("%s: %v is on two pages", path, post["Title"])
+			}
+			seen[post["ID"]] = true
+			if score := post["score"].(float64); score > lastScore {
+				t.Errorf("%s: %v ranks below a worse match", path, post["Title"])
+			} else {
+				lastScore = score
+			}
+		}
+
+		path = ""
+		if next, ok := res.Body["nextCursor"].(string); ok {
+			path = "/posts/search/karahi?limit=2&after=" + next
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("search listed %d posts, want 4", len(seen))
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	server := newTestServer(t)
+	token := signup(t, server, "Amina", "amina@example.com")
+	chef := signup(t, server, "Saffron Chef", "chef@example.com")
+
+	// each post matches "saffron" in one field only, so the order follows the field weights
+	posts := []struct{ token, body string }{
+		{token, `{"title":"Plain rice","country":"pk","recipe":"1 cup rice","description":"With a pinch of saffron"}`},
+		{chef, `{"title":"Plain rice","country":"pk","recipe":"1 cup rice"}`},
+		{token, `{"title":"Saffron rice","country":"pk","recipe":"1 cup rice"}`},
+		{token, `{"title":"Plain rice","country":"pk","recipe":"1 pinch saffron"}`},
+		{token, `{"title":"Plain rice","country":"pk","recipe":"1 cup rice"}`},
+	}
+	for _, post := range posts {
+		if res := call(t, server, "POST", "/posts", post.token, post.body); res.Code != http.StatusOK {
+			t.Fatalf("create: got %d %v", res.Code, res.Body)
+		}
+	}
+
+	res := call(t, server, "GET", "/posts/search/saffron", "", "")
+	if res.Code != http.StatusOK || res.Body["total"] != float64(4) {
+		t.Fatalf("search: got %d %v", res.Code, res.Body)
+	}
+	want := []float64{10, 4, 3, 2}
+	for i, post := range res.Body["posts"].([]interface{}) {
+		if score := post.(map[string]interface{})["score"]; score != want[i] {
+			t.Errorf("hit %d: score %v, want %v", i, score, want[i])
+		}
+	}
+
+	if res := call(t, server, "GET", "/posts/search/%20", "", ""); res.Code != http.StatusBadRequest || res.errorCode() != "INVALID_QUERY" {
+		t.Errorf("empty query: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/posts/search/a", "", ""); res.Code != http.StatusOK || res.Body["total"] != float64(0) {
+		t.Errorf("query without terms: got %d %v", res.Code, res.Body)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	app := newTestApp(t)
+	server := NewRouter(app)
+	token := signup(t, server, "Amina", "amina@example.com")
+	other := signup(t, server, "Bilal", "bilal@example.com")
+
+	res := call(t, server, "POST", "/auth/api-keys", token, `{"name":"script","scopes":["posts:read","admin"]}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "VALIDATION_FAILED" {
+		t.Errorf("unknown scope: got %d %v", res.Code, res.Body)
+	}
+
+	res = call(t, server, "POST", "/auth/api-keys", token, `{"name":"script","scopes":["posts:read","comments:write","posts:read"]}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	key, keyID := res.Body["key"].(string), res.Body["id"].(string)
+
+	// only the hash of the key is stored, under the ID the key starts with
+	objectID, _ := primitive.ObjectIDFromHex(keyID)
+	stored, err := app.APIKeys.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Hash != utils.HashToken(key) || stored.Hash == key || len(stored.Scopes) != 2 {
+		t.Errorf("stored key: %+v", stored)
+	}
+	if id, ok := utils.APIKeyID(key); !ok || id != keyID {
+		t.Errorf("APIKeyID(%q) = %q, %v", key, id, ok)
+	}
+	res = call(t, server, "GET", "/auth/api-keys", token, "")
+	if keys := res.Body["apiKeys"].([]interface{}); len(keys) != 1 || keys[0].(map[string]interface{})["key"] != nil || keys[0].(map[string]interface{})["hash"] != nil {
+		t.Errorf("list: got %v", res.Body)
+	}
+
+	forged := []string{
+		"uck_",
+		"uck_" + keyID,
+		"uck_" + keyID + "_wrongsecret",
+		"uck_" + primitive.NewObjectID().Hex() + key[len("uck_")+24:],
+		"uck_not-an-object-id-at-all_" + key[len(key)-10:],
+	}
+	for _, forged := range forged {
+		if res := call(t, server, "GET", "/feed", forged, ""); res.Code != http.StatusUnauthorized || res.errorCode() != "UNAUTHENTICATED" {
+			t.Errorf("feed with %q: got %d %v", forged, res.Code, res.Body)
+		}
+	}
+
+	// the key works within its scopes and nowhere else
+	if res := call(t, server, "GET", "/feed", key, ""); res.Code != http.StatusOK {
+		t.Errorf("feed with posts:read: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "POST", "/posts", key, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`); res.Code != http.StatusForbidden || res.errorCode() != "MISSING_SCOPE" {
+		t.Errorf("create a post without posts:write: got %d %v", res.Code, res.Body)
+	}
+	res = call(t, server, "POST", "/posts", token, `{"title":"Karahi","country":"pk","recipe":"1 kg chicken"}`)
+	postID := res.Body["ID"].(string)
+	if res := call(t, server, "POST", "/posts/"+postID+"/comments", key, `{"text":"Lovely"}`); res.Code != http.StatusCreated {
+		t.Errorf("comment with comments:write: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "POST", "/posts/"+postID+"/like", key, ""); res.Code != http.StatusForbidden || res.errorCode() != "MISSING_SCOPE" {
+		t.Errorf("like without posts:write: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/auth/api-keys", key, ""); res.Code != http.StatusForbidden || res.errorCode() != "API_KEY_NOT_ALLOWED" {
+		t.Errorf("manage keys with a key: got %d %v", res.Code, res.Body)
+	}
+
+	// revoking
+	if res := call(t, server, "DELETE", "/auth/api-keys/"+keyID, other, ""); res.Code != http.StatusNotFound || res.errorCode() != "API_KEY_NOT_FOUND" {
+		t.Errorf("revoke someone else's key: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "DELETE", "/auth/api-keys/"+keyID, token, ""); res.Code != http.StatusOK {
+		t.Fatalf("revoke: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/feed", key, ""); res.Code != http.StatusUnauthorized || res.errorCode() != "UNAUTHENTICATED" {
+		t.Errorf("feed with a revoked key: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "DELETE", "/auth/api-keys/"+keyID, token, ""); res.Code != http.StatusNotFound || res.errorCode() != "API_KEY_NOT_FOUND" {
+		t.Errorf("revoke twice: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/auth/api-keys", token, ""); len(res.Body["apiKeys"].([]interface{})) != 0 {
+		t.Errorf("list after revoking: got %v", res.Body)
+	}
+}
+
+func TestTwoFactor(t *testing.T) {
+	server := newTestServer(t)
+	token := signup(t, server, "Amina", "amina@example.com")
+	login := `{"email":"amina@example.com","password":"secret12!"}`
+
+	res := call(t, server, "POST", "/auth/2fa/setup", token, "")
+	secret, _ := res.Body["secret"].(string)
+	if res.Code != http.StatusOK || secret == "" {
+		t.Fatalf("setup: got %d %v", res.Code, res.Body)
+	}
+	now := time.Now()
+	code, _ := totp.GenerateCode(secret, now)
+	res = call(t, server, "POST", "/auth/2fa/enable", token, `{"code":"`+code+`"}`)
+	if res.Code != http.StatusOK || len(res.Body["recoveryCodes"].([]interface{})) != 10 {
+		t.Fatalf("enable: got %d %v", res.Code, res.Body)
+	}
+	recoveryCode := res.Body["recoveryCodes"].([]interface{})[0].(string)
+
+	// the password alone only earns a challenge
+	challenge := func() string {
+		t.Helper()
+		res := call(t, server, "POST", "/auth/login", "", login)
+		if res.Code != http.StatusOK || res.Body["twoFactorRequired"] != true || res.Body["token"] != nil {
+			t.Fatalf("login: got %d %v", res.Code, res.Body)
+		}
+		return res.Body["challengeToken"].(string)
+	}
+
+	// the next period's code is still accepted for clock drift, but only once
+	next, _ := totp.GenerateCode(secret, now.Add(30*time.Second))
+	res = call(t, server, "POST", "/auth/2fa/verify", "", `{"challengeToken":"`+challenge()+`","code":"`+next+`"}`)
+	if res.Code != http.StatusOK || res.Body["token"] == nil {
+		t.Errorf("verify with a code: got %d %v", res.Code, res.Body)
+	}
+	res = call(t, server, "POST", "/auth/2fa/verify", "", `{"challengeToken":"`+challenge()+`","code":"`+next+`"}`)
+	if res.Code != http.StatusUnauthorized || res.errorCode() != "INVALID_CREDENTIALS" {
+		t.Errorf("replay a code: got %d %v", res.Code, res.Body)
+	}
+
+	// recovery codes are normalized before they are checked, and work once
+	typed := strings.ToUpper(strings.Replace(recoveryCode, "-", " - ", 1))
+	res = call(t, server, "POST", "/auth/2fa/verify", "", `{"challengeToken":"`+challenge()+`","recoveryCode":"`+typed+`"}`)
+	if res.Code != http.StatusOK || res.Body["token"] == nil {
+		t.Errorf("verify with a recovery code: got %d %v", res.Code, res.Body)
+	}
+	res = call(t, server, "POST", "/auth/2fa/verify", "", `{"challengeToken":"`+challenge()+`","recoveryCode":"`+recoveryCode+`"}`)
+	if res.Code != http.StatusUnauthorized || res.errorCode() != "INVALID_CREDENTIALS" {
+		t.Errorf("reuse a recovery code: got %d %v", res.Code, res.Body)
+	}
+
+	// challenges expire, and nothing else stands in for one
+	userID := call(t, server, "GET", "/users/me", token, "").Body["id"].(string)
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userID":  userID,
+		"purpose": "2fa",
+		"exp":     now.Add(-time.Minute).Unix(),
+	}).SignedString(utils.JwtSecretKey)
+	later, _ := totp.GenerateCode(secret, now.Add(60*time.Second))
+	for name, challengeToken := range map[string]string{"expired challenge": expired, "access token": token, "no challenge": ""} {
+		res := call(t, server, "POST", "/auth/2fa/verify", "", `{"challengeToken":"`+challengeToken+`","code":"`+later+`"}`)
+		if res.Code != http.StatusUnauthorized || res.errorCode() != "SESSION_EXPIRED" {
+			t.Errorf("%s: got %d %v", name, res.Code, res.Body)
+		}
+	}
+}
+
+func TestCollections(t *testing.T) {
+	app := newTestApp(t)
+	server := NewRouter(app)
+	token := signup(t, server, "Amina", "amina@example.com")
+	other := signup(t, server, "Bilal", "bilal@example.com")
+	ownerID := call(t, server, "GET", "/users/me", token, "").Body["id"].(string)
+
+	var postIDs []string
+	for _, title := range []string{"Karahi", "Nihari", "Biryani"} {
+		res := call(t, server, "POST", "/posts", token, `{"title":"`+title+`","country":"pk","recipe":"1 kg chicken"}`)
+		postIDs = append(postIDs, res.Body["ID"].(string))
+	}
+
+	res := call(t, server, "POST", "/users/me/collections", token, `{"name":"Weeknights"}`)
+	if res.Code != http.StatusCreated || res.Body["public"] != false {
+		t.Fatalf("create: got %d %v", res.Code, res.Body)
+	}
+	id := res.Body["id"].(string)
+	for _, postID := range postIDs {
+		if res := call(t, server, "POST", "/users/me/collections/"+id+"/items", token, `{"postID":"`+postID+`"}`); res.Code != http.StatusCreated {
+			t.Fatalf("add %s: got %d %v", postID, res.Code, res.Body)
+		}
+	}
+
+	// a private collection is not found by anyone but its owner
+	if res := call(t, server, "GET", "/users/me/collections/"+id, token, ""); res.Code != http.StatusOK || len(res.Body["items"].([]interface{})) != 3 {
+		t.Errorf("owner: got %d %v", res.Code, res.Body)
+	}
+	for name, viewer := range map[string]string{"another user": other, "anonymous": ""} {
+		if res := call(t, server, "GET", "/collections/"+id, viewer, ""); res.Code != http.StatusNotFound || res.errorCode() != "COLLECTION_NOT_FOUND" {
+			t.Errorf("%s: got %d %v", name, res.Code, res.Body)
+		}
+	}
+	if res := call(t, server, "GET", "/users/me/collections/"+id, other, ""); res.Code != http.StatusNotFound || res.errorCode() != "COLLECTION_NOT_FOUND" {
+		t.Errorf("another user through /users/me: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "DELETE", "/users/me/collections/"+id, other, ""); res.Code != http.StatusNotFound || res.errorCode() != "COLLECTION_NOT_FOUND" {
+		t.Errorf("another user deleting: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/users/"+ownerID+"/collections", "", ""); len(res.Body["collections"].([]interface{})) != 0 {
+		t.Errorf("public listing shows a private collection: got %v", res.Body)
+	}
+
+	call(t, server, "PATCH", "/users/me/collections/"+id, token, `{"public":true}`)
+	if res := call(t, server, "GET", "/collections/"+id, "", ""); res.Code != http.StatusOK || res.Body["shareURL"] == nil {
+		t.Errorf("public collection: got %d %v", res.Code, res.Body)
+	}
+	if res := call(t, server, "GET", "/users/"+ownerID+"/collections", "", ""); len(res.Body["collections"].([]interface{})) != 1 {
+		t.Errorf("public listing: got %v", res.Body)
+	}
+
+	// a new order must list every post exactly once
+	path := "/users/me/collections/" + id + "/items"
+	invalid := map[string]string{
+		"missing a post":   `{"postIDs":["` + postIDs[2] + `","` + postIDs[0] + `"]}`,
+		"a post twice":     `{"postIDs":["` + postIDs[2] + `","` + postIDs[0] + `","` + postIDs[0] + `"]}`,
+		"a post not in it": `{"postIDs":["` + postIDs[2] + `","` + postIDs[0] + `","` + postIDs[1] + `","` + primitive.NewObjectID().Hex() + `"]}`,
+		"an invalid ID":    `{"postIDs":["` + postIDs[2] + `","` + postIDs[0] + `","nope"]}`,
+	}
+	for name, body := range invalid {
+		if res := call(t, server, "PUT", path, token, body); res.Code != http.StatusBadRequest || res.errorCode() != "INVALID_INPUT" {
+			t.Errorf("reorder with %s: got %d %v", name, res.Code, res.Body)
+		}
+	}
+	res = call(t, server, "PUT", path, token, `{"postIDs":["`+postIDs[2]+`","`+postIDs[0]+`","`+postIDs[1]+`"]}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("reorder: got %d %v", res.Code, res.Body)
+	}
+	for i, want := range []int{2, 0, 1} {
+		if got := res.Body["items"].([]interface{})[i].(map[string]interface{})["postID"]; got != postIDs[want] {
+			t.Errorf("item %d after reordering: got %v, want %s", i, got, postIDs[want])
+		}
+	}
+	if res := call(t, server, "PUT", path, other, `{"postIDs":["`+postIDs[0]+`","`+postIDs[1]+`","`+postIDs[2]+`"]}`); res.Code != http.StatusNotFound {
+		t.Errorf("another user reordering: got %d %v", res.Code, res.Body)
+	}
+
+	// a full collection takes no more posts
+	collectionID, _ := primitive.ObjectIDFromHex(id)
+	items := make([]models.CollectionItem, 500)
+	for i := range items {
+		items[i] = models.CollectionItem{PostID: primitive.NewObjectID()}
+	}
+	if err := app.Collections.SetItems(context.Background(), collectionID, items); err != nil {
+		t.Fatal(err)
+	}
+	res = call(t, server, "POST", path, token, `{"postID":"`+postIDs[0]+`"}`)
+	if res.Code != http.StatusBadRequest || res.errorCode() != "LIMIT_REACHED" {
+		t.Errorf("add to a full collection: got %d %v", res.Code, res.Body)
+	}
+	if err := app.Collections.SetItems(context.Background(), collectionID, items[:499]); err != nil {
+		t.Fatal(err)
+	}
+	if res := call(t, server, "POST", path, token, `{"postID":"`+postIDs[0]+`"}`); res.Code != http.StatusCreated {
+		t.Errorf("add the last post: got %d %v", res.Code, res.Body)
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/controllers"
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/middlewares"
+	"github.com/gorilla/mux"
+)
+
+func UserRoutes(router *mux.Router, app *controllers.App) {
+	optionalAuth := middlewares.OptionalAuth(app.Sessions, app.APIKeys)
+
+	// a prefix subrouter for /users/me would also catch handles starting with "me"
+	authRequired := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.AuthMiddleware(app.Sessions, app.APIKeys)(middlewares.RequireSession(handler))
+	}
+
+	router.Handle("/users/me", authRequired(app.GetMyProfile)).Methods("GET")
+	router.Handle("/users/me", authRequired(app.UpdateProfile)).Methods("PATCH")
+	router.Handle("/users/me/following", authRequired(app.GetFollowing)).Methods("GET")
+	router.Handle("/users/{id}", optionalAuth(http.HandlerFunc(app.GetProfile))).Methods("GET")
+	router.Handle("/users/{id}/posts", optionalAuth(http.HandlerFunc(app.GetUserPosts))).Methods("GET")
+	router.Handle("/users/{id}/follow", authRequired(app.FollowUser)).Methods("POST")
+	router.Handle("/users/{id}/follow", authRequired(app.UnfollowUser)).Methods("DELETE")
+}
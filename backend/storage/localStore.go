@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files under a directory, for running the API on a
+// single machine; it serves them itself under the path of its base URL
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore returns a LocalStore writing to dir, whose files are served
+// from baseURL, e.g. "http://localhost:8080/media"
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// the file is written under a temporary name first, so that readers never see half an upload
+func (store *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+
+	path := store.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	written, err := io.Copy(temp, body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("blob %q: wrote %d bytes, expected %d", key, written, size)
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+func (store *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(store.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return nil
+	}
+
+	err := os.Remove(store.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (store *LocalStore) URL(key string) string {
+	return store.baseURL + "/" + key
+}
+
+// ServeHTTP serves the blob named by the rest of the path, once the router has
+// stripped the prefix it is mounted under; directories are never listed
+func (store *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !ValidKey(key) || strings.HasPrefix(filepath.Base(key), ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	path := store.path(key)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// blobs never change under a key, so they can be cached for good
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(w, r, path)
+}
+
+func (store *LocalStore) path(key string) string {
+	return filepath.Join(store.dir, filepath.FromSlash(key))
+}
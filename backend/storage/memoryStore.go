@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStore keeps blobs in memory so tests can read them back
+type MemoryStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+	types map[string]string
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[string][]byte), types: make(map[string]string)}
+}
+
+func (store *MemoryStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.blobs[key] = data
+	store.types[key] = contentType
+	return nil
+}
+
+func (store *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, ok := store.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (store *MemoryStore) Delete(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.blobs, key)
+	delete(store.types, key)
+	return nil
+}
+
+func (store *MemoryStore) URL(key string) string {
+	return "memory://" + key
+}
+
+// Keys returns the keys of the stored blobs, in no particular order
+func (store *MemoryStore) Keys() []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := make([]string, 0, len(store.blobs))
+	for key := range store.blobs {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ContentType returns the content type the blob was stored with
+func (store *MemoryStore) ContentType(key string) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.types[key]
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Backoff counts failures per key in memory; once a key has used up its free
+// attempts, each further failure doubles how long it has to wait, up to max
+type Backoff struct {
+	mu        sync.Mutex
+	free      int
+	base      time.Duration
+	max       time.Duration
+	entries   map[string]backoffEntry
+	lastSweep time.Time
+}
+
+type backoffEntry struct {
+	failures int
+	until    time.Time
+}
+
+// NewBackoff returns a Backoff allowing free failures per key before it starts
+// making the key wait base, then twice as long after every failure
+func NewBackoff(free int, base, max time.Duration) *Backoff {
+	return &Backoff{free: free, base: base, max: max, entries: make(map[string]backoffEntry)}
+}
+
+// Wait returns how long the key has to wait before its next attempt, or zero
+func (backoff *Backoff) Wait(key string, now time.Time) time.Duration {
+	backoff.mu.Lock()
+	defer backoff.mu.Unlock()
+
+	entry, ok := backoff.entries[key]
+	if !ok || !now.Before(entry.until) {
+		return 0
+	}
+	return entry.until.Sub(now)
+}
+
+// Fail records a failed attempt for the key
+func (backoff *Backoff) Fail(key string, now time.Time) {
+	backoff.mu.Lock()
+	defer backoff.mu.Unlock()
+
+	if now.Sub(backoff.lastSweep) > backoff.max {
+		backoff.forgetIdle(now)
+		backoff.lastSweep = now
+	}
+
+	entry := backoff.entries[key]
+	entry.failures++
+	entry.until = now
+	if over := entry.failures - backoff.free; over > 0 {
+		delay := backoff.max
+		if over <= 30 && backoff.base<<(over-1) < backoff.max {
+			delay = backoff.base << (over - 1)
+		}
+		entry.until = now.Add(delay)
+	}
+	backoff.entries[key] = entry
+}
+
+// Reset forgets the key's failures, after it has succeeded
+func (backoff *Backoff) Reset(key string) {
+	backoff.mu.Lock()
+	defer backoff.mu.Unlock()
+
+	delete(backoff.entries, key)
+}
+
+// keys that have gone a whole max without failing start over, which also stops
+// the map growing without bound. Fail sweeps at most once per max, so the map is
+// not scanned on every failure
+func (backoff *Backoff) forgetIdle(now time.Time) {
+	for key, entry := range backoff.entries {
+		if now.Sub(entry.until) > backoff.max {
+			delete(backoff.entries, key)
+		}
+	}
+}
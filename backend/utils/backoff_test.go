@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	backoff := NewBackoff(2, time.Second, 8*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+
+	// the free attempts cost nothing, then the wait doubles up to the maximum
+	wants := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second}
+	for i, want := range wants {
+		backoff.Fail("ip:1", now)
+		if got := backoff.Wait("ip:1", now); got != want {
+			t.Errorf("after %d failures: wait %v, want %v", i+1, got, want)
+		}
+	}
+
+	if got := backoff.Wait("ip:1", now.Add(3*time.Second)); got != 5*time.Second {
+		t.Errorf("wait 3s later = %v, want 5s", got)
+	}
+	if got := backoff.Wait("ip:2", now); got != 0 {
+		t.Errorf("another key has to wait %v", got)
+	}
+
+	backoff.Reset("ip:1")
+	if got := backoff.Wait("ip:1", now); got != 0 {
+		t.Errorf("wait after reset = %v, want 0", got)
+	}
+}
+
+func TestBackoffDoesNotOverflow(t *testing.T) {
+	backoff := NewBackoff(0, time.Second, time.Hour)
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 100; i++ {
+		backoff.Fail("key", now)
+	}
+	if got := backoff.Wait("key", now); got != time.Hour {
+		t.Errorf("wait after 100 failures = %v, want the maximum", got)
+	}
+}
+
+func TestBackoffForgetsIdleKeys(t *testing.T) {
+	backoff := NewBackoff(0, time.Second, 8*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 3; i++ {
+		backoff.Fail("idle", now)
+	}
+
+	// a failure on any key sweeps keys that have been quiet for longer than the maximum
+	later := now.Add(time.Minute)
+	backoff.Fail("other", later)
+	if _, ok := backoff.entries["idle"]; ok {
+		t.Fatal("idle key was not forgotten")
+	}
+
+	backoff.Fail("idle", later)
+	if got := backoff.Wait("idle", later); got != time.Second {
+		t.Errorf("forgotten key starts over with a wait of %v, want 1s", got)
+	}
+}
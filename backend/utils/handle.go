@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// HandleFromName turns a display name into a handle such as "amina_khan",
+// dropping accents and anything that is not a letter or digit; names with too
+// little left over get "cook"
+func HandleFromName(name string) string {
+	var handle strings.Builder
+	lastUnderscore := true
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			handle.WriteRune(r)
+			lastUnderscore = false
+		case unicode.Is(unicode.Mn, r):
+			// accents left over from the decomposition
+		case !lastUnderscore:
+			handle.WriteByte('_')
+			lastUnderscore = true
+		}
+	}
+
+	result := strings.Trim(handle.String(), "_")
+	if len(result) > 20 {
+		result = strings.TrimRight(result[:20], "_")
+	}
+	if len(result) < 3 {
+		return "cook"
+	}
+	return result
+}
+
+// UniqueHandle returns base if it is free, and otherwise base with a random
+// number added, asking taken whether each candidate is in use
+func UniqueHandle(base string, taken func(handle string) (bool, error)) (string, error) {
+	candidate := base
+	for attempt := 0; attempt < 10; attempt++ {
+		inUse, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !inUse {
+			return candidate, nil
+		}
+
+		suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%d", base, suffix.Int64())
+	}
+	return "", fmt.Errorf("no free handle found for %q", base)
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Lockout counts failures in a row per key in memory and locks the key for a
+// while once there have been limit of them; every failure after that locks it
+// again. Keys that have not failed for a day are forgotten
+type Lockout struct {
+	mu        sync.Mutex
+	limit     int
+	duration  time.Duration
+	entries   map[string]lockoutEntry
+	lastSweep time.Time
+}
+
+type lockoutEntry struct {
+	failures int
+	lastFail time.Time
+	until    time.Time
+}
+
+const lockoutIdle = 24 * time.Hour
+
+// NewLockout returns a Lockout locking keys for duration after limit failures
+func NewLockout(limit int, duration time.Duration) *Lockout {
+	return &Lockout{limit: limit, duration: duration, entries: make(map[string]lockoutEntry)}
+}
+
+// LockedFor returns how long the key stays locked, or zero
+func (lockout *Lockout) LockedFor(key string, now time.Time) time.Duration {
+	lockout.mu.Lock()
+	defer lockout.mu.Unlock()
+
+	entry, ok := lockout.entries[key]
+	if !ok || !now.Before(entry.until) {
+		return 0
+	}
+	return entry.until.Sub(now)
+}
+
+// Fail records a failed attempt for the key
+func (lockout *Lockout) Fail(key string, now time.Time) {
+	lockout.mu.Lock()
+	defer lockout.mu.Unlock()
+
+	if now.Sub(lockout.lastSweep) > time.Hour {
+		for key, entry := range lockout.entries {
+			if now.Sub(entry.lastFail) > lockoutIdle {
+				delete(lockout.entries, key)
+			}
+		}
+		lockout.lastSweep = now
+	}
+
+	entry := lockout.entries[key]
+	entry.failures++
+	entry.lastFail = now
+	if entry.failures >= lockout.limit {
+		entry.until = now.Add(lockout.duration)
+	}
+	lockout.entries[key] = entry
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockout(t *testing.T) {
+	lockout := NewLockout(3, 10*time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 2; i++ {
+		lockout.Fail("email:a@example.com", now)
+	}
+	if got := lockout.LockedFor("email:a@example.com", now); got != 0 {
+		t.Errorf("locked for %v before the limit", got)
+	}
+
+	lockout.Fail("email:a@example.com", now)
+	if got := lockout.LockedFor("email:a@example.com", now); got != 10*time.Minute {
+		t.Errorf("locked for %v at the limit, want 10m", got)
+	}
+
+	// once the lock ends, the next failure locks the key again
+	later := now.Add(10 * time.Minute)
+	if got := lockout.LockedFor("email:a@example.com", later); got != 0 {
+		t.Errorf("still locked for %v after the lock ended", got)
+	}
+	lockout.Fail("email:a@example.com", later)
+	if got := lockout.LockedFor("email:a@example.com", later); got != 10*time.Minute {
+		t.Errorf("locked for %v after failing again, want 10m", got)
+	}
+
+	// keys quiet for a day are forgotten
+	nextDay := later.Add(25 * time.Hour)
+	lockout.Fail("email:b@example.com", nextDay)
+	lockout.Fail("email:a@example.com", nextDay)
+	if got := lockout.LockedFor("email:a@example.com", nextDay); got != 0 {
+		t.Errorf("locked for %v after a day without failures", got)
+	}
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+)
+
+// unit systems a recipe can be displayed in; an empty system keeps each
+// ingredient in the system it was written in
+const (
+	Metric   = "metric"
+	Imperial = "imperial"
+)
+
+// ScaledIngredient is an ingredient converted for display, with its quantity
+// also written out as a kitchen-friendly string such as "1 1/2"
+type ScaledIngredient struct {
+	models.Ingredient
+	Display string `json:"display"`
+}
+
+// millilitres per volume unit
+var volumeUnits = map[string]float64{
+	"tsp": 4.92892, "tbsp": 14.7868, "fl oz": 29.5735, "cup": 236.588, "ml": 1, "l": 1000,
+}
+
+// grams per mass unit
+var massUnits = map[string]float64{
+	"g": 1, "kg": 1000, "oz": 28.3495, "lb": 453.592,
+}
+
+type density struct {
+	gramsPerCup float64
+	liquid      bool // liquids stay in volume units even in metric
+}
+
+// rough densities for common ingredients, used to move between cups and grams
+var densities = map[string]density{
+	"flour":             {125, false},
+	"all-purpose flour": {125, false},
+	"bread flour":       {130, false},
+	"whole wheat flour": {120, false},
+	"chickpea flour":    {92, false},
+	"gram flour":        {92, false},
+	"semolina":          {167, false},
+	"cornstarch":        {128, false},
+	"sugar":             {200, false},
+	"brown sugar":       {220, false},
+	"powdered sugar":    {120, false},
+	"icing sugar":       {120, false},
+	"salt":              {292, false},
+	"butter":            {227, false},
+	"ghee":              {205, false},
+	"rice":              {185, false},
+	"basmati rice":      {185, false},
+	"lentils":           {192, false},
+	"oats":              {90, false},
+	"cocoa powder":      {85, false},
+	"grated cheese":     {100, false},
+	"almonds":           {143, false},
+	"water":             {236, true},
+	"milk":              {240, true},
+	"cream":             {238, true},
+	"yogurt":            {245, true},
+	"oil":               {218, true},
+	"olive oil":         {216, true},
+	"honey":             {340, true},
+	"stock":             {236, true},
+	"broth":             {236, true},
+}
+
+// ScaleRecipe scales the ingredients from the recipe's serving count to servings
+// and converts them to the given unit system
+func ScaleRecipe(recipe models.Recipe, servings int, system string) []ScaledIngredient {
+	factor := 1.0
+	if recipe.Servings > 0 && servings > 0 {
+		factor = float64(servings) / float64(recipe.Servings)
+	}
+
+	scaled := make([]ScaledIngredient, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		ingredient.Quantity *= factor
+		scaled = append(scaled, convertIngredient(ingredient, system))
+	}
+	return scaled
+}
+
+func convertIngredient(ingredient models.Ingredient, system string) ScaledIngredient {
+	if ingredient.Quantity == 0 {
+		return ScaledIngredient{Ingredient: ingredient}
+	}
+
+	target := system
+	if target == "" {
+		target = systemOf(ingredient.Unit)
+	}
+
+	ml, isVolume := volumeUnits[ingredient.Unit]
+	grams, isMass := massUnits[ingredient.Unit]
+	d, hasDensity := lookupDensity(ingredient.Name)
+
+	switch {
+	case isVolume && target == Metric && hasDensity && !d.liquid && !isSpoonAmount(ingredient.Quantity*ml, ingredient.Unit):
+		grams := ingredient.Quantity * ml / volumeUnits["cup"] * d.gramsPerCup
+		ingredient.Quantity, ingredient.Unit = pickMetricMass(grams)
+	case isVolume && target == Metric:
+		ingredient.Quantity, ingredient.Unit = pickMetricVolume(ingredient.Quantity*ml, ingredient.Unit)
+	case isVolume:
+		ingredient.Quantity, ingredient.Unit = pickImperialVolume(ingredient.Quantity * ml)
+	case isMass && target == Imperial && hasDensity:
+		cups := ingredient.Quantity * grams / d.gramsPerCup
+		ingredient.Quantity, ingredient.Unit = pickImperialVolume(cups * volumeUnits["cup"])
+	case isMass && target == Imperial:
+		ingredient.Quantity, ingredient.Unit = pickImperialMass(ingredient.Quantity * grams)
+	case isMass:
+		ingredient.Quantity, ingredient.Unit = pickMetricMass(ingredient.Quantity * grams)
+	default:
+		ingredient.Quantity = roundFraction(ingredient.Quantity)
+	}
+
+	display := FormatFraction(ingredient.Quantity)
+	if ingredient.Unit == "g" || ingredient.Unit == "kg" || ingredient.Unit == "ml" || ingredient.Unit == "l" {
+		display = strconv.FormatFloat(ingredient.Quantity, 'f', -1, 64)
+	}
+
+	return ScaledIngredient{Ingredient: ingredient, Display: display}
+}
+
+func systemOf(unit string) string {
+	switch unit {
+	case "g", "kg", "ml", "l":
+		return Metric
+	default:
+		return Imperial
+	}
+}
+
+// finds the density entry with the longest name found as whole words in the
+// ingredient name, so that "cauliflower" does not match "flour"
+func lookupDensity(name string) (density, bool) {
+	name = " " + strings.Join(strings.FieldsFunc(strings.ToLower(name), isWordSeparatorExceptDash), " ") + " "
+	best, found := "", false
+	for key := range densities {
+		if strings.Contains(name, " "+key+" ") && len(key) > len(best) {
+			best, found = key, true
+		}
+	}
+	return densities[best], found
+}
+
+func isWordSeparatorExceptDash(r rune) bool {
+	return r != '-' && isWordSeparator(r)
+}
+
+func pickMetricMass(grams float64) (float64, string) {
+	if grams >= 1000 {
+		return roundTo(grams/1000, 0.05), "kg"
+	}
+	return roundMetric(grams), "g"
+}
+
+// spoons are used the same way in metric kitchens, so small amounts keep them
+func isSpoonAmount(ml float64, unit string) bool {
+	return (unit == "tsp" || unit == "tbsp") && ml < volumeUnits["cup"]/4
+}
+
+func pickMetricVolume(ml float64, unit string) (float64, string) {
+	if isSpoonAmount(ml, unit) {
+		return pickImperialVolume(ml)
+	}
+	if ml >= 1000 {
+		return roundTo(ml/1000, 0.05), "l"
+	}
+	return roundMetric(ml), "ml"
+}
+
+func pickImperialVolume(ml float64) (float64, string) {
+	switch {
+	case ml < volumeUnits["tbsp"]:
+		return roundFraction(ml / volumeUnits["tsp"]), "tsp"
+	case ml < volumeUnits["cup"]/4:
+		return roundFraction(ml / volumeUnits["tbsp"]), "tbsp"
+	default:
+		return roundFraction(ml / volumeUnits["cup"]), "cup"
+	}
+}
+
+func pickImperialMass(grams float64) (float64, string) {
+	if grams >= massUnits["lb"] {
+		return roundFraction(grams / massUnits["lb"]), "lb"
+	}
+	return roundFraction(grams / massUnits["oz"]), "oz"
+}
+
+// small metric amounts keep half units, larger ones are rounded to 5
+func roundMetric(value float64) float64 {
+	switch {
+	case value < 10:
+		return math.Max(roundTo(value, 0.5), 0.5)
+	case value < 100:
+		return roundTo(value, 1)
+	default:
+		return roundTo(value, 5)
+	}
+}
+
+// the final rounding to two decimals removes floating point noise such as 1.1500000000000001
+func roundTo(value, step float64) float64 {
+	return math.Round(math.Round(value/step)*step*100) / 100
+}
+
+var kitchenFractions = []float64{0, 1.0 / 8, 1.0 / 4, 1.0 / 3, 1.0 / 2, 2.0 / 3, 3.0 / 4, 1}
+
+// rounds to the nearest fraction found on measuring cups and spoons
+func roundFraction(value float64) float64 {
+	if value >= 10 {
+		return math.Round(value)
+	}
+
+	whole := math.Floor(value)
+	rest := value - whole
+
+	closest := kitchenFractions[0]
+	for _, fraction := range kitchenFractions {
+		if math.Abs(rest-fraction) < math.Abs(rest-closest) {
+			closest = fraction
+		}
+	}
+
+	// never round a real amount away entirely
+	if whole+closest == 0 {
+		return kitchenFractions[1]
+	}
+	return whole + closest
+}
+
+// FormatFraction writes 1.5 as "1 1/2" and 0.333 as "1/3"
+func FormatFraction(value float64) string {
+	whole := math.Floor(value)
+	rest := value - whole
+
+	names := map[float64]string{
+		1.0 / 8: "1/8", 1.0 / 4: "1/4", 1.0 / 3: "1/3", 1.0 / 2: "1/2", 2.0 / 3: "2/3", 3.0 / 4: "3/4",
+	}
+	for fraction, name := range names {
+		if math.Abs(rest-fraction) < 0.01 {
+			if whole == 0 {
+				return name
+			}
+			return fmt.Sprintf("%.0f %s", whole, name)
+		}
+	}
+
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Abdul-Moeed-Saqib/urcuisine-backend/models"
+)
+
+func TestScaleRecipe(t *testing.T) {
+	recipe := models.Recipe{
+		Servings: 4,
+		Ingredients: []models.Ingredient{
+			{Quantity: 2, Unit: "cup", Name: "basmati rice"},
+			{Quantity: 3, Name: "eggs"},
+			{Name: "salt", Notes: "to taste"},
+		},
+	}
+
+	got := ScaleRecipe(recipe, 6, "")
+	want := []ScaledIngredient{
+		{Ingredient: models.Ingredient{Quantity: 3, Unit: "cup", Name: "basmati rice"}, Display: "3"},
+		{Ingredient: models.Ingredient{Quantity: 4.5, Name: "eggs"}, Display: "4 1/2"},
+		{Ingredient: models.Ingredient{Name: "salt", Notes: "to taste"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d ingredients, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ingredient %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// without a serving count there is nothing to scale from
+	unscaled := ScaleRecipe(models.Recipe{Ingredients: recipe.Ingredients[:1]}, 8, "")
+	if unscaled[0].Quantity != 2 {
+		t.Errorf("recipe without servings scaled to %v", unscaled[0].Quantity)
+	}
+}
+
+func TestConvertIngredient(t *testing.T) {
+	tests := []struct {
+		in      models.Ingredient
+		system  string
+		want    models.Ingredient
+		display string
+	}{
+		// solids with a known density move between cups and grams
+		{models.Ingredient{Quantity: 1, Unit: "cup", Name: "flour"}, Metric, models.Ingredient{Quantity: 125, Unit: "g", Name: "flour"}, "125"},
+		{models.Ingredient{Quantity: 500, Unit: "g", Name: "flour"}, Imperial, models.Ingredient{Quantity: 4, Unit: "cup", Name: "flour"}, "4"},
+		// liquids stay in volume
+		{models.Ingredient{Quantity: 2, Unit: "cup", Name: "milk"}, Metric, models.Ingredient{Quantity: 475, Unit: "ml", Name: "milk"}, "475"},
+		// small spoon amounts keep their spoons in metric
+		{models.Ingredient{Quantity: 1, Unit: "tsp", Name: "salt"}, Metric, models.Ingredient{Quantity: 1, Unit: "tsp", Name: "salt"}, "1"},
+		// without a density, mass stays mass
+		{models.Ingredient{Quantity: 1, Unit: "kg", Name: "chicken"}, Imperial, models.Ingredient{Quantity: 2.25, Unit: "lb", Name: "chicken"}, "2 1/4"},
+		{models.Ingredient{Quantity: 1500, Unit: "g", Name: "chicken"}, "", models.Ingredient{Quantity: 1.5, Unit: "kg", Name: "chicken"}, "1.5"},
+		// "cauliflower" must not be mistaken for flour
+		{models.Ingredient{Quantity: 1, Unit: "cup", Name: "cauliflower"}, Metric, models.Ingredient{Quantity: 235, Unit: "ml", Name: "cauliflower"}, "235"},
+	}
+	for _, test := range tests {
+		got := convertIngredient(test.in, test.system)
+		if got.Ingredient != test.want || got.Display != test.display {
+			t.Errorf("convertIngredient(%+v, %q) = %+v %q, want %+v %q", test.in, test.system, got.Ingredient, got.Display, test.want, test.display)
+		}
+	}
+}
+
+func TestRoundFraction(t *testing.T) {
+	tests := map[float64]float64{
+		0.01: 0.125, // a real amount is never rounded away
+		0.3:  1.0 / 3,
+		1.45: 1.5,
+		2.9:  3,
+		12.4: 12,
+	}
+	for in, want := range tests {
+		if got := roundFraction(in); got != want {
+			t.Errorf("roundFraction(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestFormatFraction(t *testing.T) {
+	tests := map[float64]string{
+		1.5:     "1 1/2",
+		1.0 / 3: "1/3",
+		0.125:   "1/8",
+		2:       "2",
+		1.07:    "1.07",
+	}
+	for in, want := range tests {
+		if got := FormatFraction(in); got != want {
+			t.Errorf("FormatFraction(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const snippetLength = 160
+
+// splits a search query into lowercase terms, dropping duplicates and
+// terms too short to be useful for matching
+func SearchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), isWordSeparator) {
+		if len([]rune(word)) < 2 || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+
+	return terms
+}
+
+// escapes the text and wraps every word that matches one of the terms in <mark> tags
+func Highlight(text string, terms []string) string {
+	var b strings.Builder
+
+	forEachWord(text, func(chunk string, isWord bool) {
+		if isWord && matchesAnyTerm(chunk, terms) {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(chunk))
+			b.WriteString("</mark>")
+			return
+		}
+		b.WriteString(html.EscapeString(chunk))
+	})
+
+	return b.String()
+}
+
+// returns a highlighted excerpt of the text centred on the first matching term
+func Snippet(text string, terms []string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetLength {
+		return Highlight(text, terms)
+	}
+
+	start, offset, found := 0, 0, false
+	forEachWord(text, func(chunk string, isWord bool) {
+		if !found && isWord && matchesAnyTerm(chunk, terms) {
+			start, found = offset, true
+		}
+		offset += len([]rune(chunk))
+	})
+
+	// keep some context before the match but never run past the end
+	start -= snippetLength / 4
+	if start < 0 {
+		start = 0
+	}
+	if start+snippetLength > len(runes) {
+		start = len(runes) - snippetLength
+	}
+
+	// avoid cutting words in half at the edges of the excerpt
+	end := start + snippetLength
+	for start > 0 && start < end && !isWordSeparator(runes[start-1]) {
+		start++
+	}
+	for end < len(runes) && end > start && !isWordSeparator(runes[end]) {
+		end--
+	}
+
+	excerpt := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(runes) {
+		excerpt = excerpt + "…"
+	}
+
+	return Highlight(excerpt, terms)
+}
+
+// counts how many words in the text match one of the terms
+func CountMatches(text string, terms []string) int {
+	count := 0
+	forEachWord(text, func(chunk string, isWord bool) {
+		if isWord && matchesAnyTerm(chunk, terms) {
+			count++
+		}
+	})
+	return count
+}
+
+// calls fn with alternating runs of word and non-word characters
+func forEachWord(text string, fn func(chunk string, isWord bool)) {
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		j := i
+		isWord := !isWordSeparator(runes[i])
+		for j < len(runes) && !isWordSeparator(runes[j]) == isWord {
+			j++
+		}
+		fn(string(runes[i:j]), isWord)
+		i = j
+	}
+}
+
+// a word matches when it starts with the term, which roughly mirrors the
+// stemming MongoDB applies ("tomato" matches "tomatoes")
+func matchesAnyTerm(word string, terms []string) bool {
+	word = strings.ToLower(word)
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) || (len(word) >= 4 && strings.HasPrefix(term, word)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
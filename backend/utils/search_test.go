@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"a & b", nil},
+		{"Chicken Karahi", []string{"chicken", "karahi"}},
+		{"karahi KARAHI, karahi!", []string{"karahi"}},
+		{"pão de queijo", []string{"pão", "de", "queijo"}},
+		{"<script>alert(1)</script>", []string{"script", "alert"}},
+	}
+	for _, test := range tests {
+		if got := SearchTerms(test.query); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SearchTerms(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		want  string
+	}{
+		{"Chicken Karahi", "karahi", "Chicken <mark>Karahi</mark>"},
+		{"Chicken Karahi", "", "Chicken Karahi"},
+		{"Fresh tomatoes", "tomato", "Fresh <mark>tomatoes</mark>"},
+		{"Tomato soup", "tomatoes", "<mark>Tomato</mark> soup"},
+		{"Tom's soup", "tomatoes", "Tom&#39;s soup"},
+		{"Salt & pepper", "pepper", "Salt &amp; <mark>pepper</mark>"},
+		{"<b>Karahi</b>", "karahi", "&lt;b&gt;<mark>Karahi</mark>&lt;/b&gt;"},
+		{`<img src=x onerror="alert(1)">`, "img", `&lt;<mark>img</mark> src=x onerror=&#34;alert(1)&#34;&gt;`},
+		{"Karahi, karahi & KARAHI", "karahi", "<mark>Karahi</mark>, <mark>karahi</mark> &amp; <mark>KARAHI</mark>"},
+	}
+	for _, test := range tests {
+		if got := Highlight(test.text, SearchTerms(test.query)); got != test.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", test.text, test.query, got, test.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	terms := SearchTerms("karahi")
+
+	if got := Snippet("A short <b>karahi</b> story", terms); got != "A short &lt;b&gt;<mark>karahi</mark>&lt;/b&gt; story" {
+		t.Errorf("short text: got %q", got)
+	}
+
+	long := strings.Repeat("slow cooked onions and garlic ", 10) + "then the karahi " + strings.Repeat("simmers until the oil separates ", 10)
+	got := Snippet(long, terms)
+	if !strings.Contains(got, "<mark>karahi</mark>") {
+		t.Errorf("the excerpt misses the match: %q", got)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("the excerpt is not marked as cut on both sides: %q", got)
+	}
+	plain := strings.NewReplacer("<mark>", "", "</mark>", "", "…", "").Replace(got)
+	if !strings.Contains(long, plain) {
+		t.Errorf("the excerpt cuts a word in half: %q", got)
+	}
+	if n := len([]rune(plain)); n > snippetLength {
+		t.Errorf("the excerpt is %d characters long, want at most %d", n, snippetLength)
+	}
+
+	if got := Snippet(long, nil); !strings.HasPrefix(got, "slow cooked") {
+		t.Errorf("without terms the excerpt should start at the beginning: %q", got)
+	}
+}
+
+func TestCountMatches(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		want  int
+	}{
+		{"Chicken Karahi", "karahi", 1},
+		{"Karahi, karahi & KARAHI", "karahi", 3},
+		{"Chicken Karahi", "chicken karahi", 2},
+		{"Chicken Karahi", "", 0},
+		{"Tom's soup", "tomatoes", 0},
+	}
+	for _, test := range tests {
+		if got := CountMatches(test.text, SearchTerms(test.query)); got != test.want {
+			t.Errorf("CountMatches(%q, %q) = %d, want %d", test.text, test.query, got, test.want)
+		}
+	}
+}
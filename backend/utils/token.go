@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -9,12 +15,19 @@ import (
 
 var JwtSecretKey = []byte(os.Getenv("JWT_SECRET"))
 
-// generates a new JWT token for authenticated users
-func GenerateJWT(userID string, userName string) (string, error) {
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// generates a short-lived access token for the given session
+func GenerateJWT(userID string, userName string, role string, sessionID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"userID": userID,
 		"name":   userName,
-		"exp":    time.Now().Add(time.Hour * 72).Unix(), // in 3 days it will expire
+		"role":   role,
+		"sid":    sessionID,
+		"exp":    time.Now().Add(AccessTokenTTL).Unix(),
 	})
 
 	tokenString, err := token.SignedString(JwtSecretKey)
@@ -24,3 +37,83 @@ func GenerateJWT(userID string, userName string) (string, error) {
 
 	return tokenString, nil
 }
+
+// a challenge token proves the password was right while the second factor is still to come
+const challengeTokenTTL = 5 * time.Minute
+
+// generates the short-lived token handed out between the password and the 2FA code
+func GenerateChallengeToken(userID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userID":  userID,
+		"purpose": "2fa",
+		"exp":     time.Now().Add(challengeTokenTTL).Unix(),
+	})
+	return token.SignedString(JwtSecretKey)
+}
+
+// returns the user a challenge token was issued to, if it is valid and unexpired
+func ParseChallengeToken(tokenString string) (string, bool) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return JwtSecretKey, nil
+	})
+	if err != nil || !token.Valid || claims["purpose"] != "2fa" {
+		return "", false
+	}
+	userID, ok := claims["userID"].(string)
+	return userID, ok
+}
+
+// generates a random URL-safe token, such as the ones sent in account emails
+func GenerateToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// generates a refresh token of the form "<sessionID>.<secret>"; only its hash is stored
+func GenerateRefreshToken(sessionID string) (string, error) {
+	secret, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	return sessionID + "." + secret, nil
+}
+
+// APIKeyPrefix starts every API key, telling them apart from access tokens
+const APIKeyPrefix = "uck_"
+
+// generates an API key of the form "uck_<keyID>_<secret>"; only its hash is stored
+func GenerateAPIKey(keyID string) (string, error) {
+	secret, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + keyID + "_" + secret, nil
+}
+
+// returns the ID of the key an API key string belongs to
+func APIKeyID(key string) (string, bool) {
+	rest := strings.TrimPrefix(key, APIKeyPrefix)
+	if !strings.HasPrefix(key, APIKeyPrefix) || len(rest) < 26 || rest[24] != '_' {
+		return "", false
+	}
+	return rest[:24], true
+}
+
+// returns the session ID a refresh token belongs to
+func RefreshTokenSession(token string) (string, bool) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	return sessionID, ok && sessionID != "" && secret != ""
+}
+
+// hashes a token for storage; generated tokens are random enough that a plain SHA-256 will do
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
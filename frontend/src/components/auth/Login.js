@@ -4,10 +4,14 @@ import { Box, Button, Heading, Input, FormControl, FormLabel, Text, VStack, Form
 import { useNavigate } from 'react-router-dom';
 
 const Login = () => {
-  const { login } = useAuth();
+  const { login, verifyTwoFactor } = useAuth();
   const navigate = useNavigate();
   const [credentials, setCredentials] = useState({ email: '', password: '' });
   const [errors, setErrors] = useState({ email: '', password: '' });
+  // set once the password is accepted for an account with 2FA on
+  const [challengeToken, setChallengeToken] = useState(null);
+  const [code, setCode] = useState('');
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
 
   const handleChange = (e) => {
     setCredentials({ ...credentials, [e.target.name]: e.target.value });
@@ -27,17 +31,115 @@ const Login = () => {
     }
 
     try {
-      await login(credentials);
+      const challenge = await login(credentials);
+      if (challenge) {
+        setChallengeToken(challenge);
+        return;
+      }
       navigate('/');
     } catch (err) {
-      if (err.response && err.response.data) {
-        setErrors(err.response.data);
+      const apiError = err.response?.data?.error;
+      if (apiError) {
+        const details = apiError.details || {};
+        setErrors({
+          email: details.email || '',
+          password: details.password || '',
+          general: details.email || details.password ? '' : apiError.message,
+        });
       } else {
         setErrors({ general: 'Failed to log in. Please try again.' });
       }
     }
   };
 
+  const handleVerify = async (e) => {
+    e.preventDefault();
+    setErrors({});
+
+    if (!code) {
+      setErrors({ code: useRecoveryCode ? 'Recovery code is required.' : 'Code is required.' });
+      return;
+    }
+
+    try {
+      await verifyTwoFactor(challengeToken, useRecoveryCode ? { recoveryCode: code } : { code });
+      navigate('/');
+    } catch (err) {
+      const apiError = err.response?.data?.error;
+      // the challenge only lasts a few minutes, after which the password is needed again
+      if (apiError?.code === 'SESSION_EXPIRED') {
+        setChallengeToken(null);
+        setCode('');
+        setErrors({ general: apiError.message });
+      } else if (apiError) {
+        setErrors({ code: apiError.details?.code || '', general: apiError.details?.code ? '' : apiError.message });
+      } else {
+        setErrors({ general: 'Failed to verify the code. Please try again.' });
+      }
+    }
+  };
+
+  const toggleRecoveryCode = () => {
+    setUseRecoveryCode(!useRecoveryCode);
+    setCode('');
+    setErrors({});
+  };
+
+  if (challengeToken) {
+    return (
+      <Box
+        minW="500px" 
+        maxW="600px" 
+        mx="auto"
+        mt={10}
+        p={8}
+        borderWidth={1}
+        borderRadius="lg"
+        boxShadow="lg"
+        bg="white">
+        <Heading mb={6} textAlign="center" fontWeight="bold" color="teal.500">
+          Two-Factor Authentication
+        </Heading>
+        <form onSubmit={handleVerify}>
+          <VStack spacing={4}>
+            <Text color="gray.600">
+              {useRecoveryCode
+                ? 'Enter one of the recovery codes you saved when you turned on two-factor authentication.'
+                : 'Enter the 6-digit code from your authenticator app.'}
+            </Text>
+            <FormControl isInvalid={errors.code}>
+              <FormLabel>{useRecoveryCode ? 'Recovery code' : 'Code'}</FormLabel>
+              <Input
+                name="code"
+                value={code}
+                onChange={(e) => { setCode(e.target.value); setErrors({}); }}
+                autoComplete="one-time-code"
+                inputMode={useRecoveryCode ? 'text' : 'numeric'}
+                autoFocus
+                focusBorderColor="teal.400"
+                borderColor="gray.300"
+                _hover={{ borderColor: 'teal.500' }}
+              />
+              <FormErrorMessage>{errors.code}</FormErrorMessage>
+            </FormControl>
+            {errors.general && <Text color="red.500">{errors.general}</Text>}
+            <Button
+              type="submit"
+              colorScheme="teal"
+              size="lg"
+              width="full"
+              mt={4}>
+              Verify
+            </Button>
+            <Button variant="link" colorScheme="teal" onClick={toggleRecoveryCode}>
+              {useRecoveryCode ? 'Use a code from your app instead' : 'Use a recovery code instead'}
+            </Button>
+          </VStack>
+        </form>
+      </Box>
+    );
+  }
+
   return (
     <Box
       minW="500px" 
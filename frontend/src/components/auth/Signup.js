@@ -36,12 +36,14 @@ const Signup = () => {
       await signup(credentials);
       navigate('/');
     } catch (err) {
-      if (err.response && err.response.data) {
-        const { email, password, name } = err.response.data;
+      const apiError = err.response?.data?.error;
+      if (apiError) {
+        const details = apiError.details || {};
         setErrors({
-          name: name || '',
-          email: email || '',
-          password: password || '',
+          name: details.name || '',
+          email: details.email || '',
+          password: details.password || '',
+          general: apiError.details ? '' : apiError.message,
         });
       } else {
         setErrors((prev) => ({
@@ -107,7 +109,7 @@ const Signup = () => {
             />
             <FormErrorMessage>{errors.password}</FormErrorMessage>
           </FormControl>
-          {errors.form && <Text color="red.500">{errors.form}</Text>}
+          {errors.general && <Text color="red.500">{errors.general}</Text>}
           <Button
             type="submit"
             colorScheme="teal"
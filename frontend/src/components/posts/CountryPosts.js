@@ -14,7 +14,7 @@ const CountryPosts = () => {
         setLoading(true);
         axios.get(`/posts/country/${country}`)
             .then((response) => {
-                setPosts(response.data);
+                setPosts(response.data.posts);
                 setLoading(false);
             })
             .catch((error) => {
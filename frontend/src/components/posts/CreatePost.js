@@ -23,6 +23,7 @@ import {
 } from '@chakra-ui/react';
 import countryList from 'country-list';
 import axios from 'axios';
+import { ingredientLines } from '../../utils/recipe';
 
 const CreatePost = () => {
   const navigate = useNavigate();
@@ -82,7 +83,7 @@ const CreatePost = () => {
       return;
     }
 
-    const postData = { ...formData, recipe: formData.recipe.join(', ') }; // array to string
+    const postData = { ...formData, recipe: ingredientLines(formData.recipe) };
 
     try {
       const response = await axios.post('/posts', postData, { withCredentials: true });
@@ -20,6 +20,7 @@ import {
   ListItem, 
   ListIcon  } from '@chakra-ui/react';
   import { MinusIcon } from '@chakra-ui/icons';
+import { formatIngredient } from '../../utils/recipe';
 
 const PostDetails = () => {
   const { id } = useParams();
@@ -28,7 +29,11 @@ const PostDetails = () => {
   const toast = useToast();
 
   const [post, setPost] = useState(null);
+  const [comments, setComments] = useState([]);
+  const [commentsCursor, setCommentsCursor] = useState(null);
   const [commentText, setCommentText] = useState('');
+  const [replyingTo, setReplyingTo] = useState(null);
+  const [replyText, setReplyText] = useState('');
   const [relatedPosts, setRelatedPosts] = useState([]);
   const [liked, setLiked] = useState(false);
   const [disliked, setDisliked] = useState(false);
@@ -37,22 +42,12 @@ const PostDetails = () => {
     const fetchPost = async () => {
       try {
         const response = await axios.get(`/posts/${id}`);
-        const { post: postData, likesList, dislikeList } = response.data;
-
-        if (user) {
-          const liked = likesList?.includes(id);
-          const disliked = dislikeList?.includes(id);
-
-          setLiked(liked);
-          setDisliked(disliked);
-
-          postData.Comments = postData.Comments?.sort((a, b) => {
-            if (a.UserID === user.id) return -1;
-            if (b.UserID === user.id) return 1;
-    
-            return new Date(b.Created * 1000) - new Date(a.Created * 1000);
-          });
+        // signed-in users get the post alongside the reactions they left on it
+        const { post: postData, myReactions } = response.data;
 
+        if (user && postData) {
+          setLiked(myReactions.includes('like'));
+          setDisliked(myReactions.includes('dislike'));
           setPost(postData)
         } else {
           setPost(response.data);
@@ -65,7 +60,7 @@ const PostDetails = () => {
     const fetchRelatedPosts = async () => {
       try {
         const response = await axios.get(`/posts/${id}/related`);
-        setRelatedPosts(response.data);
+        setRelatedPosts(response.data.posts);
       } catch (error) {
         console.error('Error fetching related posts', error);
       }
@@ -75,6 +70,31 @@ const PostDetails = () => {
     fetchRelatedPosts();
   }, [id, user]);
 
+  // comments come a page of threads at a time, newest first, each with its replies
+  useEffect(() => {
+    const fetchComments = async () => {
+      try {
+        const response = await axios.get(`/posts/${id}/comments`);
+        setComments(response.data.comments || []);
+        setCommentsCursor(response.data.nextCursor);
+      } catch (error) {
+        console.error('Error fetching comments', error);
+      }
+    };
+
+    fetchComments();
+  }, [id]);
+
+  const handleMoreComments = async () => {
+    try {
+      const response = await axios.get(`/posts/${id}/comments`, { params: { after: commentsCursor } });
+      setComments((prevComments) => [...prevComments, ...(response.data.comments || [])]);
+      setCommentsCursor(response.data.nextCursor);
+    } catch (error) {
+      console.error('Error fetching comments', error);
+    }
+  };
+
   const handleLike = async () => {
     if (!user) {
       toast({
@@ -177,16 +197,37 @@ const PostDetails = () => {
         withCredentials: true
       });
 
-      setPost((prevPost) => ({
-        ...prevPost,
-        Comments: [response.data, ...(prevPost.Comments || [])],
-      }));
+      setComments((prevComments) => [{ ...response.data, Replies: [] }, ...prevComments]);
       setCommentText('');
     } catch (error) {
       console.error('Error adding comment', error);
     }
   };
 
+  const handleReply = async (e, parent) => {
+    e.preventDefault();
+
+    try {
+      const response = await axios.post(`/posts/${id}/comments`, {
+        text: replyText,
+        parentID: parent.ID
+      }, {
+        withCredentials: true
+      });
+
+      // replies go at the end of their parent's replies, wherever it is in the tree
+      const addReply = (threads) => threads.map((thread) => thread.ID === parent.ID
+        ? { ...thread, Replies: [...(thread.Replies || []), { ...response.data, Replies: [] }] }
+        : { ...thread, Replies: addReply(thread.Replies || []) });
+
+      setComments((prevComments) => addReply(prevComments));
+      setReplyingTo(null);
+      setReplyText('');
+    } catch (error) {
+      console.error('Error adding reply', error);
+    }
+  };
+
   const handleUpdatePost = () => {
     navigate(`/post/${id}/edit`);
   };
@@ -203,6 +244,55 @@ const PostDetails = () => {
   };
 
 
+  const renderComment = (comment) => (
+    <Flex
+      key={comment.ID}
+      p={3}
+      shadow="md"
+      borderWidth="1px"
+      borderRadius="md"
+      w="full"
+      direction="column"
+      alignItems="flex-start"
+    >
+      <Text fontSize="sm" color="gray.600">
+        {comment.Name} - {new Date(comment.Created * 1000).toLocaleString()}
+      </Text>
+      <Text mt={1}>{comment.Text}</Text>
+
+      {user && !comment.Deleted && !comment.Hidden && (
+        <Button size="xs" variant="link" mt={2} onClick={() => { setReplyingTo(comment.ID); setReplyText(''); }}>
+          Reply
+        </Button>
+      )}
+
+      {replyingTo === comment.ID && (
+        <Box as="form" onSubmit={(e) => handleReply(e, comment)} mt={2} w="full">
+          <Textarea
+            placeholder={`Reply to ${comment.Name}...`}
+            value={replyText}
+            onChange={(e) => setReplyText(e.target.value)}
+            mb={2}
+          />
+          <HStack>
+            <Button type="submit" size="sm" colorScheme="blue" isDisabled={!replyText}>
+              Reply
+            </Button>
+            <Button size="sm" onClick={() => setReplyingTo(null)}>
+              Cancel
+            </Button>
+          </HStack>
+        </Box>
+      )}
+
+      {comment.Replies && comment.Replies.length > 0 && (
+        <VStack align="start" spacing={3} mt={3} pl={6} w="full">
+          {comment.Replies.map(renderComment)}
+        </VStack>
+      )}
+    </Flex>
+  );
+
   if (!post) return <div>Loading...</div>;
 
   const youtubeEmbedUrl = getYouTubeEmbedUrl(post.video_url);
@@ -255,10 +345,10 @@ const PostDetails = () => {
           <Box mt={4} ml={20} textAlign="left">
             <Heading size="md" mb={2}>Recipe:</Heading>
             <List spacing={2}>
-              {post.Recipe.split(',').map((ingredient, index) => (
+              {(post.Recipe?.ingredients || []).map((ingredient, index) => (
                 <ListItem key={index}>
                   <ListIcon as={MinusIcon} color="green.500" />
-                  {ingredient.trim()}
+                  {formatIngredient(ingredient)}
                 </ListItem>
               ))}
             </List>
@@ -294,25 +384,14 @@ const PostDetails = () => {
 
             <Divider my={4} />
 
-            {post.Comments && post.Comments.length > 0 ? (
+            {comments.length > 0 ? (
               <VStack align="start" spacing={4} mt={2} w="full">
-                {post.Comments.map((comment, index) => (
-                  <Flex
-                    key={index}
-                    p={3}
-                    shadow="md"
-                    borderWidth="1px"
-                    borderRadius="md"
-                    w="full"
-                    direction="column"
-                    alignItems="flex-start"
-                  >
-                    <Text fontSize="sm" color="gray.600">
-                      {comment.Name} - {new Date(comment.Created * 1000).toLocaleString()}
-                    </Text>
-                    <Text mt={1}>{comment.Text}</Text>
-                  </Flex>
-                ))}
+                {comments.map(renderComment)}
+                {commentsCursor && (
+                  <Button variant="outline" onClick={handleMoreComments}>
+                    Load more comments
+                  </Button>
+                )}
               </VStack>
             ) : (
               <Text>No comments yet. Be the first to comment!</Text>
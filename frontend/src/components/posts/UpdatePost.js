@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { useAuth } from '../../context/AuthContext';
 import axios from 'axios';
+import { formatIngredient, ingredientLines } from '../../utils/recipe';
 import {
   Box,
   Flex,
@@ -41,15 +42,19 @@ const UpdatePost = () => {
   const [post, setPost] = useState(null);
   const [recipeValue, setRecipeValue] = useState('');
   const [tags, setTags] = useState([]);
+  const [originalTags, setOriginalTags] = useState([]);
 
   useEffect(() => {
     const fetchPost = async () => {
       try {
         const response = await axios.get(`/posts/${id}`);
-        const { post: postData } = response.data;
+        // signed-in users get the post alongside their reactions
+        const postData = response.data.post || response.data;
+        const ingredients = (postData.Recipe?.ingredients || []).map(formatIngredient);
 
         setPost(postData);
-        setTags(postData.Recipe ? postData.Recipe.split(',') : []);
+        setTags(ingredients);
+        setOriginalTags(ingredients);
       } catch (error) {
         console.error('Error fetching post details', error);
       }
@@ -97,7 +102,13 @@ const UpdatePost = () => {
     }
 
     try {
-      await axios.put(`/posts/${id}`, { ...post, Recipe: tags.join(', ') });
+      // only what the form edits is sent; the recipe is left alone, steps and all, unless its ingredients changed
+      const update = { title: post.Title, description: post.Description };
+      if (ingredientLines(tags) !== ingredientLines(originalTags)) {
+        update.recipe = ingredientLines(tags);
+      }
+
+      await axios.patch(`/posts/${id}`, update);
       toast({ title: 'Post updated!', status: 'success', duration: 3000 });
       navigate(`/post/${id}`);
     } catch (error) {
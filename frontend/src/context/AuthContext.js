@@ -6,6 +6,16 @@ const AuthContext = createContext();
 
 const AUTH_STATE_KEY = 'authState'; 
 
+// the access token and refresh token are cookies, and the refresh token is only
+// sent to /auth, so every request has to carry credentials
+axios.defaults.withCredentials = true;
+
+// requests that must not trigger a refresh, since they are how a session starts or renews
+const NO_REFRESH_URLS = ['/auth/login', '/auth/2fa/verify', '/auth/signup', '/auth/refresh', '/auth/logout'];
+
+// a refresh token works only once, so requests failing together share one refresh
+let refreshing = null;
+
 export const AuthProvider = ({ children }) => {
   const [user, setUser] = useState(null);
   const [loading, setLoading] = useState(true);
@@ -30,13 +40,57 @@ export const AuthProvider = ({ children }) => {
     return authState === 'true';
   };
 
+  const setUserFromToken = (token) => {
+    const decodedToken = decodeToken(token); 
+    setUser({ name: decodedToken.name, id: decodedToken.userID }); 
+  };
+
+  // trades the refresh token for a new access token, keeping the user signed in
+  const refresh = () => {
+    if (!refreshing) {
+      refreshing = axios.post('/auth/refresh', {}, { withCredentials: true })
+        .then((response) => {
+          setUserFromToken(response.data.token);
+          setAuthState('true');
+          return response.data.token;
+        })
+        .catch((error) => {
+          setUser(null);
+          setAuthState('false');
+          throw error;
+        })
+        .finally(() => {
+          refreshing = null;
+        });
+    }
+    return refreshing;
+  };
+
+  // resolves to the challenge to pass to verifyTwoFactor when the account has
+  // 2FA on, or to null once the user is signed in
   const login = async (credentials) => {
     try {
       const response = await axios.post('/auth/login', credentials);
 
-      const token = response.data.token; 
-      const decodedToken = decodeToken(token); 
-      setUser({ name: decodedToken.name, id: decodedToken.userID }); 
+      if (response.data.twoFactorRequired && !response.data.token) {
+        return response.data.challengeToken;
+      }
+
+      setUserFromToken(response.data.token);
+
+      setAuthState('true'); 
+      return null;
+    } catch (error) {
+      throw error;
+    }
+  };
+
+  // finishes a login with a code from the user's authenticator app, or one of their recovery codes
+  const verifyTwoFactor = async (challengeToken, { code, recoveryCode }) => {
+    try {
+      const response = await axios.post('/auth/2fa/verify', { challengeToken, code, recoveryCode });
+
+      setUserFromToken(response.data.token);
 
       setAuthState('true'); 
     } catch (error) {
@@ -48,9 +102,7 @@ export const AuthProvider = ({ children }) => {
     try {
       const response = await axios.post('/auth/signup', credentials);
 
-      const token = response.data.token; 
-      const decodedToken = decodeToken(token); 
-      setUser({ name: decodedToken.name, id: decodedToken.userID  }); 
+      setUserFromToken(response.data.token);
 
       setAuthState('true'); 
     } catch (error) {
@@ -70,31 +122,42 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
+  // access tokens only last 15 minutes; when one has expired, refresh it and
+  // send the request again
+  useEffect(() => {
+    const interceptor = axios.interceptors.response.use(undefined, async (error) => {
+      const { config, response } = error;
+      const expired = response?.status === 401 || response?.data?.error?.code === 'SESSION_EXPIRED';
+
+      if (!expired || !config || config.retried || NO_REFRESH_URLS.includes(config.url) || !isAuthValid()) {
+        throw error;
+      }
+
+      config.retried = true;
+      try {
+        await refresh();
+      } catch (refreshError) {
+        throw error;
+      }
+      return axios(config);
+    });
+
+    return () => axios.interceptors.response.eject(interceptor);
+  }, []);
+
   useEffect(() => {
     const checkAuth = async () => {
       if (!isAuthValid()) {
-        console.log('Skipping token validation due to invalid or expired auth state');
+        console.log('Skipping token refresh due to invalid or expired auth state');
         setLoading(false);
         return;
       }
 
+      // the access token may have expired while the app was closed
       try {
-        const response = await axios.get('/auth/validate', {
-          withCredentials: true, 
-        });
-
-        const token = response.data.token; 
-        if (token) {
-          const decodedToken = decodeToken(token); 
-          setUser({ name: decodedToken.name, id: decodedToken.userID  }); 
-        } else {
-          setAuthState('false'); 
-          setLoading(false);
-        }
+        await refresh();
       } catch (error) {
-        console.error('Failed to validate token on refresh', error);
-        setAuthState('false'); 
-        setLoading(false);
+        console.error('Failed to refresh session on load', error);
       } finally {
         setLoading(false);
       }
@@ -104,7 +167,7 @@ export const AuthProvider = ({ children }) => {
   }, []);
 
   return (
-    <AuthContext.Provider value={{ user, login, signup, logout, loading}}>
+    <AuthContext.Provider value={{ user, login, verifyTwoFactor, signup, logout, loading}}>
       {children}
     </AuthContext.Provider>
   );
@@ -10,7 +10,7 @@ const Home = () => {
     const fetchPosts = async () => {
       try {
         const response = await axios.get('/posts');
-        setPosts(response.data);
+        setPosts(response.data.posts);
       } catch (error) {
         console.error('Error fetching posts', error);
       }
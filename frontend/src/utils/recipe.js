@@ -0,0 +1,13 @@
+// shows an ingredient the way it was written, e.g. "1.5 cup basmati rice (washed)"
+export const formatIngredient = (ingredient) => {
+  const parts = [];
+  if (ingredient.quantity) parts.push(ingredient.quantity);
+  if (ingredient.unit) parts.push(ingredient.unit);
+  parts.push(ingredient.name);
+
+  const text = parts.join(' ');
+  return ingredient.notes ? `${text} (${ingredient.notes})` : text;
+};
+
+// the backend reads one ingredient per line, parsing quantities and units out of them
+export const ingredientLines = (tags) => tags.join('\n');